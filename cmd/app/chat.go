@@ -0,0 +1,198 @@
+package main
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/source"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	markdown "github.com/Klaus-Tockloth/go-term-markdown"
+	"go.temporal.io/sdk/client"
+)
+
+type chatTurnOutput struct {
+	ChatID    string              `json:"chatId"`
+	Question  string              `json:"question"`
+	Answer    string              `json:"answer"`
+	Citations []activity.Citation `json:"citations"`
+}
+
+// runChat asks questions about a video that was already summarized, one per
+// line of stdin, or only -question. The chat stays open between runs, so
+// -resume picks up the conversation where it was left.
+func runChat(ctx context.Context, args []string) error {
+	c := newCLI("chat [flags] <url | file | podcast feed url>")
+	episode := c.fs.String("episode", "", "podcast episode GUID, number (1 is the latest) or part of its title, makes the argument a podcast feed")
+	resume := c.fs.String("resume", "", "continue the chat with this ID instead of starting one")
+	question := c.fs.String("question", "", "ask only this question instead of reading questions from stdin")
+
+	positional, err := c.parse(args)
+
+	if err != nil {
+		return err
+	}
+
+	if (*resume == "") != (len(positional) == 1) || len(positional) > 1 {
+		c.fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	chatID := *resume
+
+	if chatID == "" {
+		ref := source.FromPodcast(positional[0], *episode)
+
+		if *episode == "" {
+			ref, err = source.Parse(positional[0])
+
+			if err != nil {
+				return fmt.Errorf("invalid source: %w", err)
+			}
+		}
+
+		chatID, err = startChat(ctx, c.temporalClient, ref)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(os.Stderr, "Chat %s started, continue it later with -resume %s\n", chatID, chatID)
+	}
+
+	ask := func(q string) error {
+		turn, err := workflow.AskQuestion(ctx, c.temporalClient, chatID, q)
+
+		if err != nil {
+			return fmt.Errorf("no answer because %s", chatFailure(ctx, c.temporalClient, chatID, err))
+		}
+
+		return c.print(chatTurnOutput{chatID, turn.Question, turn.Answer, turn.Citations}, formatTurn(turn))
+	}
+
+	if *question != "" {
+		return ask(*question)
+	}
+
+	return readQuestions(os.Stdin, "", ask)
+}
+
+func startChat(ctx context.Context, temporalClient client.Client, ref source.Ref) (string, error) {
+	run, err := workflow.StartVideoChatWorkflow(ctx, temporalClient, workflow.NewChatID(), workflow.VideoChatParams{Source: ref})
+
+	if err != nil {
+		return "", fmt.Errorf("failed to start chat workflow: %w", err)
+	}
+
+	return run.GetID(), nil
+}
+
+// readQuestions calls ask with every non-empty line of r until EOF, or an
+// empty line when prompt is set, showing prompt on stderr before each one.
+func readQuestions(r io.Reader, prompt string, ask func(question string) error) error {
+	scanner := bufio.NewScanner(r)
+
+	for {
+		fmt.Fprint(os.Stderr, prompt)
+
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		q := strings.TrimSpace(scanner.Text())
+
+		if q == "" {
+			if prompt != "" {
+				return nil
+			}
+
+			continue
+		}
+
+		if err := ask(q); err != nil {
+			return err
+		}
+	}
+}
+
+// formatTurn renders an answer with the moments of the video it cites.
+func formatTurn(turn activity.ChatTurn) string {
+	var b strings.Builder
+
+	b.WriteString(strings.TrimSpace(turn.Answer))
+	b.WriteString("\n")
+
+	for _, c := range turn.Citations {
+		if c.URL != "" {
+			fmt.Fprintf(&b, "\n- [%s](%s)", c.Timestamp, c.URL)
+		}
+	}
+
+	return b.String()
+}
+
+// chatFailure says why a question got no answer, which for a question the
+// chat rejected may be why the chat itself failed.
+func chatFailure(ctx context.Context, temporalClient client.Client, chatID string, err error) string {
+	if workflow.IsInvalidUpdate(err) {
+		state, queryErr := workflow.QueryChatState(ctx, temporalClient, chatID)
+
+		if queryErr == nil && state.Error != "" {
+			return state.Error
+		}
+	}
+
+	return workflow.FailureReason(err)
+}
+
+// chatAbout offers to answer questions about a video right after its summary
+// is shown in the wizard. The chat starts with the first question.
+func chatAbout(ctx context.Context, temporalClient client.Client, ref source.Ref) {
+	chatID := ""
+
+	err := readQuestions(os.Stdin, questionStr("\n💬 Any questions about it? Ask away, or press enter to finish: "), func(q string) error {
+		if chatID == "" {
+			var err error
+
+			if chatID, err = startChat(ctx, temporalClient, ref); err != nil {
+				return err
+			}
+		}
+
+		turn, err := workflow.AskQuestion(ctx, temporalClient, chatID, q)
+
+		if err != nil {
+			fmt.Println(dangerStr("No answer because %s", chatFailure(ctx, temporalClient, chatID, err)))
+
+			if workflow.IsInvalidUpdate(err) {
+				return errors.New("the chat has ended")
+			}
+
+			return nil
+		}
+
+		fmt.Println(string(markdown.Render(formatTurn(turn), 80, 6)))
+
+		return nil
+	})
+
+	if err != nil {
+		fmt.Println(dangerStr("%v", err))
+	}
+
+	if chatID != "" {
+		util.LogInfo(fmt.Sprintf("Continue this chat any time with: chat -resume %s", chatID))
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"api/internal/summary/workflow"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+type cleanupOutput struct {
+	Objects        int                   `json:"objects"`
+	ReclaimedBytes int64                 `json:"reclaimedBytes"`
+	Kinds          []artifactCleanupKind `json:"kinds"`
+}
+
+type artifactCleanupKind struct {
+	Kind    string `json:"kind"`
+	Objects int    `json:"objects"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// runCleanup removes expired artifacts right away instead of waiting for the
+// worker's schedule, using the retention of the local configuration.
+func runCleanup(ctx context.Context, args []string) error {
+	c := newCLI("cleanup [flags]")
+
+	if _, err := c.parseArgs(args, 0); err != nil {
+		return err
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	report, err := workflow.RunCleanup(ctx, c.temporalClient, c.cfg.RetentionPolicy())
+
+	if err != nil {
+		return fmt.Errorf("cleanup failed: %w", err)
+	}
+
+	out := cleanupOutput{
+		Objects:        report.Objects,
+		ReclaimedBytes: report.ReclaimedBytes,
+		Kinds:          make([]artifactCleanupKind, 0, len(report.Kinds)),
+	}
+
+	var text strings.Builder
+	w := tabwriter.NewWriter(&text, 0, 0, 2, ' ', 0)
+
+	for _, k := range report.Kinds {
+		out.Kinds = append(out.Kinds, artifactCleanupKind{string(k.Kind), k.Objects, k.Bytes})
+		fmt.Fprintf(w, "%s\t%d objects\t%s\n", k.Kind, k.Objects, formatBytes(k.Bytes))
+	}
+
+	fmt.Fprintf(w, "Total\t%d objects\t%s\n", report.Objects, formatBytes(report.ReclaimedBytes))
+	w.Flush()
+
+	return c.print(out, text.String())
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"api/internal/config"
+	"api/internal/summary/activity"
+	"api/internal/summary/language"
+	"api/internal/summary/render"
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"api/internal/summary/style"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// commands are the non-interactive subcommands. Without one the app runs
+// the interactive wizard.
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"summarize": runSummarize,
+	"search":    runSearch,
+	"status":    runStatus,
+	"result":    runResult,
+	"list":      runList,
+	"cancel":    runCancel,
+	"cleanup":   runCleanup,
+	"styles":    runStyles,
+	"chat":      runChat,
+	"find":      runFind,
+	"index":     runIndex,
+}
+
+// runCommand runs the subcommand named by args[0], reporting false when
+// there is none.
+func runCommand(ctx context.Context, args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	run, ok := commands[args[0]]
+
+	if !ok {
+		return false
+	}
+
+	if err := run(ctx, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, dangerStr("%v", err))
+		os.Exit(1)
+	}
+
+	return true
+}
+
+// cli holds what a subcommand needs once its flags are parsed. Results go to
+// stdout, as JSON with -json, and errors to stderr.
+type cli struct {
+	fs          *flag.FlagSet
+	jsonOutput  *bool
+	configFlags *config.Flags
+
+	cfg            *config.Config
+	temporalClient client.Client
+	store          storage.Storage
+}
+
+// newCLI sets up the flags shared by every subcommand. usage shows how the
+// subcommand is invoked, as in "status [flags] <workflow-id>".
+func newCLI(usage string) *cli {
+	name, _, _ := strings.Cut(usage, " ")
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+
+	c := &cli{
+		fs:          fs,
+		jsonOutput:  fs.Bool("json", false, "print the result as JSON"),
+		configFlags: config.RegisterFlags(fs),
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n", os.Args[0], usage)
+		fs.PrintDefaults()
+	}
+
+	return c
+}
+
+// parse parses flags placed before or after the positional arguments, loads
+// the configuration and returns the positional arguments.
+func (c *cli) parse(args []string) ([]string, error) {
+	positional := make([]string, 0)
+
+	for {
+		if err := c.fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if c.fs.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, c.fs.Arg(0))
+		args = c.fs.Args()[1:]
+	}
+
+	cfg, err := c.configFlags.Load()
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	workflow.Configure(cfg.WorkflowSettings())
+	c.cfg = cfg
+
+	return positional, nil
+}
+
+// parseArgs is parse for commands taking exactly n positional arguments.
+func (c *cli) parseArgs(args []string, n int) ([]string, error) {
+	positional, err := c.parse(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(positional) != n {
+		c.fs.Usage()
+		os.Exit(2)
+	}
+
+	return positional, nil
+}
+
+func (c *cli) connect(ctx context.Context) error {
+	clientOptions, err := c.cfg.Temporal.ClientOptions()
+
+	if err != nil {
+		return fmt.Errorf("invalid Temporal configuration: %w", err)
+	}
+
+	clientOptions.Logger = util.CustomLogger{}
+
+	c.temporalClient, err = client.DialContext(ctx, clientOptions)
+
+	if err != nil {
+		return fmt.Errorf("unable to create Temporal client: %w", err)
+	}
+
+	c.store, err = storage.New(ctx, c.cfg.Storage)
+
+	if err != nil {
+		c.temporalClient.Close()
+		return fmt.Errorf("unable to create artifact storage: %w", err)
+	}
+
+	return nil
+}
+
+func (c *cli) close() {
+	if c.temporalClient != nil {
+		c.temporalClient.Close()
+	}
+}
+
+// print writes v as JSON with -json, and the text form otherwise.
+func (c *cli) print(v any, text string) error {
+	if *c.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		return enc.Encode(v)
+	}
+
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+
+	_, err := fmt.Print(text)
+
+	return err
+}
+
+type summaryOutput struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId"`
+	Status     string `json:"status"`
+	OutputKey  string `json:"outputKey,omitempty"`
+	// File is where a non-markdown summary was downloaded to.
+	File string `json:"file,omitempty"`
+	// Summary is the summary itself, for text formats.
+	Summary string `json:"summary,omitempty"`
+}
+
+func runSummarize(ctx context.Context, args []string) error {
+	c := newCLI("summarize [flags] <url | file | podcast feed url>")
+	formatFlag := c.fs.String("format", string(render.FormatMarkdown), "summary output format: md, html, json, epub or pdf")
+	force := c.fs.Bool("force", false, "download, transcribe and summarize again even if the source is cached")
+	episode := c.fs.String("episode", "", "podcast episode GUID, number (1 is the latest) or part of its title, makes the argument a podcast feed")
+	output := c.fs.String("output", "", "file to write the summary to, stdout for markdown and the file name of the summary otherwise")
+	detach := c.fs.Bool("detach", false, "print the workflow ID and exit without waiting for the summary")
+	languagesFlag := c.fs.String("language", "", languageUsage)
+	styleFlag := c.fs.String("style", "", styleUsage)
+
+	positional, err := c.parseArgs(args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	format, err := render.ParseFormat(*formatFlag)
+
+	if err != nil {
+		return err
+	}
+
+	languages, err := parseLanguages(*languagesFlag)
+
+	if err != nil {
+		return err
+	}
+
+	ref := source.FromPodcast(positional[0], *episode)
+
+	if *episode == "" {
+		ref, err = source.Parse(positional[0])
+
+		if err != nil {
+			return fmt.Errorf("invalid source: %w", err)
+		}
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	run, err := workflow.StartSummarizeWorkflow(
+		ctx,
+		c.temporalClient,
+		fmt.Sprintf("summarize-workflow-%s", uuid.New().String()),
+		workflow.SummarizeWorkflowParams{
+			Source:    ref,
+			Format:    format,
+			Force:     *force,
+			Languages: languages,
+			Style:     *styleFlag,
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to start summarize workflow: %w", err)
+	}
+
+	out := summaryOutput{
+		WorkflowID: run.GetID(),
+		RunID:      run.GetRunID(),
+		Status:     enums.WORKFLOW_EXECUTION_STATUS_RUNNING.String(),
+	}
+
+	if *detach {
+		return c.print(out, out.WorkflowID)
+	}
+
+	out.OutputKey, err = waitWithProgress(ctx, c.temporalClient, run, os.Stderr)
+
+	if err != nil {
+		return fmt.Errorf("workflow %s failed because %s", out.WorkflowID, workflow.FailureReason(err))
+	}
+
+	out.Status = enums.WORKFLOW_EXECUTION_STATUS_COMPLETED.String()
+
+	// The workflow returns the summary in the spoken language, and the one
+	// asked for first is printed instead.
+	if len(languages) > 0 {
+		out.OutputKey = activity.LanguageOutputKey(out.OutputKey, languages[0])
+	}
+
+	return c.printSummary(ctx, out, *output)
+}
+
+const languageUsage = "comma-separated languages to write the summary in, e.g. en,de, the first one is printed. Defaults to the spoken language"
+
+const styleUsage = "summary style, name or name@version, see the styles command. Defaults to " + style.Default
+
+// parseLanguages turns the value of a -language flag into language codes.
+func parseLanguages(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	return language.Parse(strings.Split(value, ","))
+}
+
+// printSummary fetches the summary stored at out.OutputKey. Text formats are
+// printed unless output names a file, other formats are always downloaded.
+func (c *cli) printSummary(ctx context.Context, out summaryOutput, output string) error {
+	format, err := render.ParseFormat(path.Ext(out.OutputKey))
+
+	if err != nil {
+		return err
+	}
+
+	textFormat := format == render.FormatMarkdown || format == render.FormatHTML || format == render.FormatJSON
+
+	if textFormat && output == "" {
+		content, err := storage.ReadAll(ctx, c.store, out.OutputKey)
+
+		if err != nil {
+			return fmt.Errorf("failed to read summary: %w", err)
+		}
+
+		out.Summary = string(content)
+
+		return c.print(out, out.Summary)
+	}
+
+	if output == "" {
+		output = path.Base(out.OutputKey)
+	}
+
+	if err := storage.Download(ctx, c.store, out.OutputKey, output); err != nil {
+		return fmt.Errorf("failed to download summary: %w", err)
+	}
+
+	out.File = output
+
+	return c.print(out, output)
+}
+
+func runSearch(ctx context.Context, args []string) error {
+	c := newCLI("search [flags] <query>")
+	duration := c.fs.String("duration", "", "video length: short (under 4 minutes), medium (4 to 20 minutes) or long (over 20 minutes)")
+	sortBy := c.fs.String("sort", "", "result order: relevance, date, rating, title or viewCount")
+
+	positional, err := c.parse(args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 {
+		c.fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	results, err := workflow.ExecuteSearchWorkflow(ctx, c.temporalClient, shared.SearchParams{
+		Topic:    strings.Join(positional, " "),
+		Duration: *duration,
+		Sort_BY:  *sortBy,
+	})
+
+	if err != nil {
+		return fmt.Errorf("search failed because %s", workflow.FailureReason(err))
+	}
+
+	if results == nil {
+		results = []shared.SearchResult{}
+	}
+
+	var text strings.Builder
+
+	for i, r := range results {
+		fmt.Fprintf(&text, "[%d]: %s (%s)\n     %s\n", i+1, r.Title, r.Origin, r.Ref())
+	}
+
+	return c.print(results, text.String())
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"api/internal/summary/workflow"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type passageOutput struct {
+	VideoID   string  `json:"videoId"`
+	Title     string  `json:"title"`
+	Kind      string  `json:"kind"`
+	Timestamp string  `json:"timestamp"`
+	URL       string  `json:"url"`
+	Text      string  `json:"text"`
+	Score     float64 `json:"score"`
+}
+
+type indexOutput struct {
+	Videos   int `json:"videos"`
+	Passages int `json:"passages"`
+}
+
+// runFind searches the transcripts and summaries of every processed video
+// for the passages closest in meaning to the query.
+func runFind(ctx context.Context, args []string) error {
+	c := newCLI("find [flags] <query>")
+	limit := c.fs.Int("limit", 10, "number of passages to return")
+
+	positional, err := c.parse(args)
+
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 || *limit <= 0 {
+		c.fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	passages, err := workflow.ExecuteFindWorkflow(ctx, c.temporalClient, strings.Join(positional, " "), *limit)
+
+	if err != nil {
+		return fmt.Errorf("find failed because %s", workflow.FailureReason(err))
+	}
+
+	out := make([]passageOutput, 0, len(passages))
+
+	var text strings.Builder
+
+	for i, p := range passages {
+		out = append(out, passageOutput{p.VideoID, p.Title, string(p.Kind), p.Timestamp, p.Link, p.Text, p.Score})
+
+		title := p.Title
+
+		if title == "" {
+			title = p.VideoID
+		}
+
+		at := string(p.Kind)
+
+		if p.Timestamp != "" {
+			at += " at " + p.Timestamp
+		}
+
+		fmt.Fprintf(&text, "[%d]: %s (%s)\n     %s\n     %s\n", i+1, title, at, p.Link, excerpt(p.Text, 200))
+	}
+
+	if len(passages) == 0 {
+		text.WriteString("Nothing found, run the index command if videos were summarized before the index existed.\n")
+	}
+
+	return c.print(out, text.String())
+}
+
+// runIndex adds every processed video to the semantic index, for videos
+// summarized before it existed. Videos indexed already are skipped unless
+// they changed.
+func runIndex(ctx context.Context, args []string) error {
+	c := newCLI("index [flags]")
+
+	if _, err := c.parseArgs(args, 0); err != nil {
+		return err
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	report, err := workflow.RunIndexLibrary(ctx, c.temporalClient)
+
+	if err != nil {
+		return fmt.Errorf("indexing failed because %s", workflow.FailureReason(err))
+	}
+
+	return c.print(
+		indexOutput{report.Videos, report.Passages},
+		fmt.Sprintf("Indexed %d videos, %d passages\n", report.Videos, report.Passages),
+	)
+}
+
+// excerpt shortens text to about n characters on one line.
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+
+	if len([]rune(text)) <= n {
+		return text
+	}
+
+	return strings.TrimSpace(string([]rune(text)[:n])) + "…"
+}
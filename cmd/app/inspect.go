@@ -0,0 +1,291 @@
+package main
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/language"
+	"api/internal/summary/workflow"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+)
+
+type workflowStatus struct {
+	WorkflowID string     `json:"workflowId"`
+	RunID      string     `json:"runId"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	StartTime  time.Time  `json:"startTime"`
+	CloseTime  *time.Time `json:"closeTime,omitempty"`
+	// Error says why a workflow that did not complete failed.
+	Error string `json:"error,omitempty"`
+}
+
+func newWorkflowStatus(info *workflowpb.WorkflowExecutionInfo) workflowStatus {
+	s := workflowStatus{
+		WorkflowID: info.GetExecution().GetWorkflowId(),
+		RunID:      info.GetExecution().GetRunId(),
+		Type:       info.GetType().GetName(),
+		Status:     info.GetStatus().String(),
+		StartTime:  info.GetStartTime().AsTime().Local(),
+	}
+
+	if info.GetCloseTime() != nil {
+		closeTime := info.GetCloseTime().AsTime().Local()
+		s.CloseTime = &closeTime
+	}
+
+	return s
+}
+
+func runStatus(ctx context.Context, args []string) error {
+	c := newCLI("status [flags] <workflow-id>")
+
+	positional, err := c.parseArgs(args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	resp, err := c.temporalClient.DescribeWorkflowExecution(ctx, positional[0], "")
+
+	if err != nil {
+		return fmt.Errorf("failed to describe workflow %s: %w", positional[0], err)
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	s := newWorkflowStatus(info)
+
+	if info.GetStatus() != enums.WORKFLOW_EXECUTION_STATUS_RUNNING && info.GetStatus() != enums.WORKFLOW_EXECUTION_STATUS_COMPLETED {
+		err := c.temporalClient.GetWorkflow(ctx, s.WorkflowID, s.RunID).Get(ctx, nil)
+		s.Error = workflow.FailureReason(err)
+	}
+
+	var text strings.Builder
+	w := tabwriter.NewWriter(&text, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Workflow ID:\t%s\n", s.WorkflowID)
+	fmt.Fprintf(w, "Run ID:\t%s\n", s.RunID)
+	fmt.Fprintf(w, "Type:\t%s\n", s.Type)
+	fmt.Fprintf(w, "Status:\t%s\n", s.Status)
+	fmt.Fprintf(w, "Started:\t%s\n", s.StartTime.Format(time.DateTime))
+
+	if s.CloseTime != nil {
+		fmt.Fprintf(w, "Closed:\t%s\n", s.CloseTime.Format(time.DateTime))
+	}
+
+	if s.Error != "" {
+		fmt.Fprintf(w, "Error:\t%s\n", s.Error)
+	}
+
+	w.Flush()
+
+	return c.print(s, text.String())
+}
+
+// resultWorkflowTypes are the workflows whose result is a stored summary.
+var resultWorkflowTypes = []string{"SummarizeWorkflow", "PlaylistSummarizeWorkflow"}
+
+func runResult(ctx context.Context, args []string) error {
+	c := newCLI("result [flags] <workflow-id>")
+	wait := c.fs.Bool("wait", false, "wait for a running workflow to finish instead of failing")
+	output := c.fs.String("output", "", "file to write the summary to, stdout for text formats and the file name of the summary otherwise")
+	languageFlag := c.fs.String("language", "", "fetch the summary in this language, one of those it was requested in")
+
+	positional, err := c.parseArgs(args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	lang := language.Code(*languageFlag)
+
+	if *languageFlag != "" && lang == "" {
+		return fmt.Errorf("unknown language %q", *languageFlag)
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	resp, err := c.temporalClient.DescribeWorkflowExecution(ctx, positional[0], "")
+
+	if err != nil {
+		return fmt.Errorf("failed to describe workflow %s: %w", positional[0], err)
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	workflowType := info.GetType().GetName()
+
+	if !slices.Contains(resultWorkflowTypes, workflowType) {
+		return fmt.Errorf("%s workflows have no summary to fetch, only %s do", workflowType, strings.Join(resultWorkflowTypes, " and "))
+	}
+
+	if lang != "" && workflowType != "SummarizeWorkflow" {
+		return errors.New("-language only applies to SummarizeWorkflow")
+	}
+
+	if info.GetStatus() == enums.WORKFLOW_EXECUTION_STATUS_RUNNING && !*wait {
+		return errors.New("workflow is still running, pass -wait to wait for it")
+	}
+
+	out := summaryOutput{
+		WorkflowID: info.GetExecution().GetWorkflowId(),
+		RunID:      info.GetExecution().GetRunId(),
+	}
+
+	run := c.temporalClient.GetWorkflow(ctx, out.WorkflowID, out.RunID)
+
+	if info.GetStatus() == enums.WORKFLOW_EXECUTION_STATUS_RUNNING && workflowType == "SummarizeWorkflow" {
+		out.OutputKey, err = waitWithProgress(ctx, c.temporalClient, run, os.Stderr)
+	} else {
+		err = run.Get(ctx, &out.OutputKey)
+	}
+
+	if err != nil {
+		return fmt.Errorf("workflow %s failed because %s", out.WorkflowID, workflow.FailureReason(err))
+	}
+
+	out.Status = enums.WORKFLOW_EXECUTION_STATUS_COMPLETED.String()
+
+	if lang != "" {
+		out.OutputKey = activity.LanguageOutputKey(out.OutputKey, lang)
+	}
+
+	return c.printSummary(ctx, out, *output)
+}
+
+func runList(ctx context.Context, args []string) error {
+	c := newCLI("list [flags]")
+	statusFlag := c.fs.String("status", "", "only list workflows with this status, e.g. running, completed or failed")
+	typeFlag := c.fs.String("type", "", "only list workflows of this type, e.g. SummarizeWorkflow")
+	limit := c.fs.Int("limit", 20, "list at most this many workflows, newest first")
+
+	if _, err := c.parseArgs(args, 0); err != nil {
+		return err
+	}
+
+	conditions := make([]string, 0, 2)
+
+	if *typeFlag != "" {
+		conditions = append(conditions, fmt.Sprintf("WorkflowType = '%s'", *typeFlag))
+	}
+
+	if *statusFlag != "" {
+		status, err := parseWorkflowStatus(*statusFlag)
+
+		if err != nil {
+			return err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("ExecutionStatus = '%s'", status))
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	list := make([]workflowStatus, 0, *limit)
+	req := &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: c.cfg.Temporal.Namespace,
+		PageSize:  int32(*limit),
+		Query:     strings.Join(conditions, " AND "),
+	}
+
+	for len(list) < *limit {
+		resp, err := c.temporalClient.ListWorkflow(ctx, req)
+
+		if err != nil {
+			return fmt.Errorf("failed to list workflows: %w", err)
+		}
+
+		for _, info := range resp.GetExecutions() {
+			if len(list) == *limit {
+				break
+			}
+
+			list = append(list, newWorkflowStatus(info))
+		}
+
+		if len(resp.GetNextPageToken()) == 0 {
+			break
+		}
+
+		req.NextPageToken = resp.GetNextPageToken()
+	}
+
+	var text strings.Builder
+	w := tabwriter.NewWriter(&text, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKFLOW ID\tTYPE\tSTATUS\tSTARTED")
+
+	for _, s := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.WorkflowID, s.Type, s.Status, s.StartTime.Format(time.DateTime))
+	}
+
+	w.Flush()
+
+	return c.print(list, text.String())
+}
+
+// parseWorkflowStatus accepts a status name in any case, as in "running" or
+// "TimedOut".
+func parseWorkflowStatus(s string) (string, error) {
+	for name, value := range enums.WorkflowExecutionStatus_shorthandValue {
+		if value != int32(enums.WORKFLOW_EXECUTION_STATUS_UNSPECIFIED) && strings.EqualFold(name, s) {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown workflow status %q", s)
+}
+
+type cancelOutput struct {
+	Canceled []string `json:"canceled"`
+}
+
+func runCancel(ctx context.Context, args []string) error {
+	c := newCLI("cancel [flags] <workflow-id>")
+
+	positional, err := c.parseArgs(args, 1)
+
+	if err != nil {
+		return err
+	}
+
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	defer c.close()
+
+	canceled, err := workflow.CancelWorkflow(ctx, c.temporalClient, positional[0])
+
+	if err != nil {
+		return fmt.Errorf("failed to cancel workflow %s: %w", positional[0], err)
+	}
+
+	var text strings.Builder
+
+	for _, id := range canceled {
+		fmt.Fprintf(&text, "Canceled %s\n", id)
+	}
+
+	return c.print(cancelOutput{canceled}, text.String())
+}
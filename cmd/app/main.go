@@ -1,17 +1,23 @@
 package main
 
 import (
+	"api/internal/config"
+	"api/internal/summary/activity"
+	"api/internal/summary/render"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
 	"api/internal/summary/workflow"
 	"api/internal/util"
 	"context"
+	"flag"
 	"fmt"
 	"os"
-	"strconv"
+	"path"
 	"time"
 
 	markdown "github.com/Klaus-Tockloth/go-term-markdown"
 	"github.com/fatih/color"
-	"go.temporal.io/api/enums/v1"
+	"github.com/google/uuid"
 	"go.temporal.io/sdk/client"
 )
 
@@ -19,166 +25,209 @@ var questionStr = color.New(color.FgGreen, color.Bold).Add(color.Underline).Spri
 var dangerStr = color.New(color.FgRed, color.Bold).SprintfFunc()
 
 func main() {
-	ctx := context.Background()
-	topic := util.StringPrompt(questionStr("✨ Ready to discover something new? \n📖 Please tell me what topic you'd like me to search and summarize? "))
+	if runCommand(context.Background(), os.Args[1:]) {
+		return
+	}
 
-	temporalClient, err := client.Dial(client.Options{
-		HostPort:  client.DefaultHostPort,
-		Namespace: "summarize",
-		Logger:    util.CustomLogger{},
-	})
+	formatFlag := flag.String("format", string(render.FormatMarkdown), "summary output format: md, html, json, epub or pdf")
+	force := flag.Bool("force", false, "download, transcribe and summarize again even if the video is cached")
+	playlistURL := flag.String("playlist", "", "summarize every video of this playlist or channel URL instead of searching")
+	concurrency := flag.Int("concurrency", 3, "how many playlist videos are summarized at once")
+	maxVideos := flag.Int("max-videos", 0, "summarize at most this many playlist videos, 0 for the limit of 500")
+	subscribeChannel := flag.String("subscribe", "", "summarize new uploads of this channel URL, handle or ID automatically")
+	pollInterval := flag.Duration("poll-interval", 6*time.Hour, "how often subscribed channels are checked for new uploads")
+	unsubscribeChannel := flag.String("unsubscribe", "", "stop summarizing new uploads of this channel")
+	showSubscriptions := flag.Bool("subscriptions", false, "list subscribed channels")
+	sourceFlag := flag.String("source", "", "summarize this URL or local audio or video file instead of searching")
+	podcastFeed := flag.String("podcast", "", "summarize an episode of this podcast RSS feed instead of searching")
+	resumeSessionID := flag.String("resume", "", "reattach to the interactive session with this ID")
+	showSessions := flag.Bool("sessions", false, "list interactive sessions that are still open")
+	languagesFlag := flag.String("language", "", languageUsage)
+	styleFlag := flag.String("style", "", styleUsage)
+	episode := flag.String("episode", "", "podcast episode GUID, number (1 is the latest) or part of its title, latest if empty")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
 
 	if err != nil {
-		dangerStr("Unable to create Temporal Client: %v\n", err)
+		fmt.Println(dangerStr("Invalid configuration: %v", err))
+		os.Exit(1)
 	}
 
-	defer temporalClient.Close()
+	workflow.Configure(cfg.WorkflowSettings())
 
-	var workflowId string
+	format, err := render.ParseFormat(*formatFlag)
 
-	workflowStatus := enums.WORKFLOW_EXECUTION_STATUS_UNSPECIFIED
-
-	if workflowId == "" {
-		workflowId = fmt.Sprintf("interaction-workflow-%v", time.Now())
+	if err != nil {
+		fmt.Println(dangerStr("%v", err))
+		os.Exit(1)
 	}
 
-	resp, err := temporalClient.DescribeWorkflowExecution(ctx, workflowId, "")
+	languages, err := parseLanguages(*languagesFlag)
 
-	if err == nil {
-		workflowStatus = resp.WorkflowExecutionInfo.Status
+	if err != nil {
+		fmt.Println(dangerStr("%v", err))
+		os.Exit(1)
 	}
 
-	var iwf client.WorkflowRun
-
-	if workflowStatus != enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
-		iwf, err = temporalClient.ExecuteWorkflow(
-			ctx,
-			client.StartWorkflowOptions{
-				ID:        workflowId,
-				TaskQueue: "summarize",
-			},
-			workflow.InteractiveWorkflow,
-			struct{ InitQuery string }{
-				InitQuery: topic,
-			},
-		)
-
-		if err != nil {
-			fmt.Printf("Failed to create new workflow: %v", err.Error())
-			panic("Failed to create new workflow")
-		}
-	}
+	ctx := context.Background()
 
-	util.LogInfo(
-		"Just a sec! ⏳ I'm quickly assessing your topic to tailor the best follow-up questions for you. 🚀",
-	)
+	clientOptions, err := cfg.Temporal.ClientOptions()
 
-	selectedUrl := ""
+	if err != nil {
+		fmt.Println(dangerStr("Invalid Temporal configuration: %v", err))
+		os.Exit(1)
+	}
 
-	for iwf != nil {
-		stateResult, err := temporalClient.QueryWorkflow(
-			ctx,
-			iwf.GetID(),
-			iwf.GetRunID(),
-			workflow.QueryCheckState,
-		)
+	clientOptions.Logger = util.CustomLogger{}
 
-		var workflowState workflow.InteractiveWorkflowState
+	temporalClient, err := client.Dial(clientOptions)
 
-		if err != nil {
-			panic("Could not retrieve workflow state")
-		}
+	if err != nil {
+		fmt.Println(dangerStr("Unable to create Temporal Client: %v", err))
+		os.Exit(1)
+	}
 
-		if workflowState.Status == workflow.StatusPending {
-			time.Sleep(time.Second * 2)
-			continue
-		}
+	defer temporalClient.Close()
 
-		if workflowState.Status == workflow.StatusCompleted {
-			break
-		}
+	store, err := storage.New(ctx, cfg.Storage)
 
-		stateResult.Get(&workflowState)
+	if err != nil {
+		fmt.Println(dangerStr("Unable to create artifact storage: %v", err))
+		os.Exit(1)
+	}
 
-		if workflowState.Status == workflow.StatusAwaitsRefinement {
-			answers := make([]string, 0)
+	switch {
+	case *subscribeChannel != "":
+		subscribe(ctx, temporalClient, workflow.SubscriptionParams{
+			Channel:   *subscribeChannel,
+			Format:    format,
+			Languages: languages,
+			Style:     *styleFlag,
+		}, *pollInterval)
+		return
+	case *unsubscribeChannel != "":
+		unsubscribe(ctx, temporalClient, *unsubscribeChannel)
+		return
+	case *showSubscriptions:
+		listSubscriptions(ctx, temporalClient)
+		return
+	case *showSessions:
+		listSessions(ctx, temporalClient)
+		return
+	case *resumeSessionID != "":
+		resumeSession(ctx, temporalClient, store, *resumeSessionID, format, *force, languages, *styleFlag)
+		return
+	}
 
-			for i, q := range workflowState.RefinementQuestions {
-				a := util.StringPrompt(questionStr("%s", q))
-				if i == len(workflowState.RefinementQuestions)-1 {
-					fmt.Println("")
-				}
-				answers = append(answers, a)
-			}
+	if *sourceFlag != "" || *podcastFeed != "" {
+		ref := source.FromPodcast(*podcastFeed, *episode)
 
-			_, err := temporalClient.QueryWorkflow(ctx, iwf.GetID(), iwf.GetRunID(), workflow.QueryAnswer, answers)
+		if *sourceFlag != "" {
+			ref, err = source.Parse(*sourceFlag)
 
 			if err != nil {
-				panic("Failed to make workflow query with answers")
+				fmt.Println(dangerStr("Invalid source: %v", err))
+				os.Exit(1)
 			}
+		}
 
-			util.LogInfo(
-				"The hunt is on! 🕵️‍♀️ I'm busy finding exciting video results tailored just for you. Get ready for some insights! ✨",
-			)
+		util.LogInfo(fmt.Sprintf("On it! 📥 Fetching %s, ✍️ transcribing it and pulling out the most important points. ⬇️ 🎧 ✍️ 💡", ref))
 
-			continue
-		}
+		summarize(ctx, temporalClient, store, workflow.SummarizeWorkflowParams{
+			Source:    ref,
+			Format:    format,
+			Force:     *force,
+			Languages: languages,
+			Style:     *styleFlag,
+		})
+		return
+	}
 
-		if workflowState.Status == workflow.StatusAwaitsSelection && len(workflowState.SearchResults) > 0 {
-			question := questionStr(
-				"Results are in! 🎬 Which video would you like me to summarize? Select one from the list below: \n",
-			)
+	if *playlistURL != "" {
+		summarizePlaylist(ctx, temporalClient, store, workflow.PlaylistSummarizeWorkflowParams{
+			URL:         *playlistURL,
+			Format:      format,
+			Force:       *force,
+			Concurrency: *concurrency,
+			MaxVideos:   *maxVideos,
+			Languages:   languages,
+			Style:       *styleFlag,
+		})
+		return
+	}
 
-			for i, v := range workflowState.SearchResults {
-				question += questionStr("[%d]: %s \n", i+1, v.Title)
-			}
+	topic := util.StringPrompt(questionStr("✨ Ready to discover something new? \n📖 Please tell me what topic you'd like me to search and summarize? "))
 
-			answer := util.StringPrompt(question)
+	startSession(ctx, temporalClient, store, topic, format, *force, languages, *styleFlag)
+}
 
-			selected, err := strconv.ParseInt(answer, 10, 64)
+// summarize runs SummarizeWorkflow and shows its summary.
+func summarize(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	params workflow.SummarizeWorkflowParams,
+) {
+	run, err := workflow.StartSummarizeWorkflow(
+		ctx,
+		temporalClient,
+		fmt.Sprintf("summarize-workflow-%s", uuid.New().String()),
+		params,
+	)
 
-			if err != nil {
-				fmt.Printf("Invalid input, please enter a number: %v\n", err)
-				continue
-			}
+	if err != nil {
+		fmt.Println(dangerStr("Failed to start summarize workflow: %v", err))
+		os.Exit(1)
+	}
+
+	showSummary(ctx, temporalClient, store, run, params.Languages)
+	chatAbout(ctx, temporalClient, params.Source)
+}
 
-			_, err = temporalClient.QueryWorkflow(
-				ctx,
-				iwf.GetID(),
-				iwf.GetRunID(),
-				workflow.QuerySearchSelection,
-				selected,
-			)
+// showSummary waits for a SummarizeWorkflow run while showing its progress
+// and either renders the markdown summary in the terminal or downloads the
+// file of any other format. The summary is shown in the first of languages
+// when the run wrote it, and in the spoken language otherwise.
+func showSummary(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	run client.WorkflowRun,
+	languages []string,
+) {
+	outputKey, err := waitWithProgress(ctx, temporalClient, run, os.Stdout)
 
-			if err != nil {
-				panic("Failed to query search selection")
-			}
+	if err != nil {
+		fmt.Println(dangerStr("Summarization failed because %s", workflow.FailureReason(err)))
+		os.Exit(1)
+	}
+
+	if len(languages) > 0 {
+		key := activity.LanguageOutputKey(outputKey, languages[0])
 
-			selectedUrl = workflowState.SearchResults[selected-1].URL
-			iwf = nil
-			continue
+		if exists, err := store.Exists(ctx, key); err == nil && exists {
+			outputKey = key
 		}
 	}
 
-	util.LogInfo(
-		"That's the one! 🎯 Alright, consider it done. \nI'm now going to 📥 grab that video, ✍️ listen to every word to write it all down, and then pull out the most important points for your summary.\nAlmost there! ⬇️ 🎧 ✍️ 💡",
-	)
+	if path.Ext(outputKey) != "."+string(render.FormatMarkdown) {
+		fileName := path.Base(outputKey)
 
-	outputPath, err := workflow.ExecuteSummarizeWorkflow(
-		ctx,
-		temporalClient,
-		workflow.SummarizeWorkflowParams{URL: selectedUrl},
-	)
+		if err := storage.Download(ctx, store, outputKey, fileName); err != nil {
+			panic(err)
+		}
 
-	if err != nil {
-		panic("Failed to execute summarize workflow")
+		util.LogInfo(fmt.Sprintf("Mission complete! ✨\nThe summary is ready at %s", fileName))
+		return
 	}
 
 	util.LogInfo(
-		"Mission complete! ✨\nThe summary of your chosen video is now ready for you to explore. Check below to discover the highlights! 👇\n\n\n",
+		"Mission complete! ✨\nThe summary is now ready for you to explore. Check below to discover the highlights! 👇\n\n\n",
 	)
 
-	source, err := os.ReadFile(outputPath)
+	source, err := storage.ReadAll(ctx, store, outputKey)
 
 	if err != nil {
 		panic(err)
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"api/internal/summary/storage"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+)
+
+func summarizePlaylist(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	params workflow.PlaylistSummarizeWorkflowParams,
+) {
+	util.LogInfo(
+		"Playlist mode! 📚 I'm collecting every video and summarizing them a few at a time. This can take a while, grab a coffee ☕",
+	)
+
+	run, err := workflow.StartPlaylistSummarizeWorkflow(
+		ctx,
+		temporalClient,
+		fmt.Sprintf("playlist-workflow-%s", uuid.New().String()),
+		params,
+	)
+
+	if err != nil {
+		panic(fmt.Errorf("failed to start playlist summarize workflow: %w", err))
+	}
+
+	var indexKey string
+
+	if err := run.Get(ctx, &indexKey); err != nil {
+		fmt.Println(dangerStr("Playlist summarization failed because %s", workflow.FailureReason(err)))
+		return
+	}
+
+	fileName := path.Base(indexKey)
+
+	if err := storage.Download(ctx, store, indexKey, fileName); err != nil {
+		panic(err)
+	}
+
+	util.LogInfo(fmt.Sprintf(
+		"Mission complete! ✨\nThe playlist index is ready at %s. It is also stored as %s, next to the summaries it links.",
+		fileName,
+		indexKey,
+	))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"api/internal/summary/workflow"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"go.temporal.io/sdk/client"
+)
+
+var doneStr = color.New(color.FgGreen).SprintFunc()
+var mutedStr = color.New(color.Faint).SprintFunc()
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const progressBarWidth = 20
+
+// waitWithProgress waits for a SummarizeWorkflow run and returns its output
+// key. While it runs, its progress is redrawn on w every second when w is a
+// terminal.
+func waitWithProgress(
+	ctx context.Context,
+	temporalClient client.Client,
+	run client.WorkflowRun,
+	w *os.File,
+) (string, error) {
+	var outputKey string
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- run.Get(ctx, &outputKey)
+	}()
+
+	if !isatty.IsTerminal(w.Fd()) && !isatty.IsCygwinTerminal(w.Fd()) {
+		return outputKey, <-done
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	display := &progressDisplay{w: w}
+
+	for {
+		select {
+		case err := <-done:
+			if err == nil {
+				progress, queryErr := workflow.GetProgress(ctx, temporalClient, run.GetID(), run.GetRunID())
+
+				if queryErr == nil {
+					display.render(progress, time.Now())
+				}
+			}
+
+			fmt.Fprintln(w)
+
+			return outputKey, err
+		case <-ticker.C:
+			progress, err := workflow.GetProgress(ctx, temporalClient, run.GetID(), run.GetRunID())
+
+			// The query fails until a worker has picked up the workflow.
+			if err != nil {
+				continue
+			}
+
+			display.render(progress, time.Now())
+		}
+	}
+}
+
+// progressDisplay redraws the progress of a workflow in place.
+type progressDisplay struct {
+	w     io.Writer
+	lines int
+	frame int
+}
+
+func (d *progressDisplay) render(p workflow.Progress, now time.Time) {
+	var b strings.Builder
+
+	if d.lines > 0 {
+		fmt.Fprintf(&b, "\033[%dA", d.lines)
+	}
+
+	title := p.Title
+
+	if title == "" {
+		title = "your summary"
+	}
+
+	lines := []string{
+		fmt.Sprintf("Working on %s %s", title, mutedStr(formatElapsed(p.Elapsed(now)))),
+	}
+
+	for _, s := range p.Stages {
+		lines = append(lines, d.stageLine(s, p.Stage, now))
+	}
+
+	for _, line := range lines {
+		b.WriteString("\r\033[2K")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	d.lines = len(lines)
+	d.frame++
+
+	io.WriteString(d.w, b.String())
+}
+
+func (d *progressDisplay) stageLine(s workflow.StageProgress, current workflow.Stage, now time.Time) string {
+	name := fmt.Sprintf("%-13s", s.Stage)
+
+	switch {
+	case s.Cached:
+		return fmt.Sprintf("  %s %s %s", doneStr("✔"), name, mutedStr("cached"))
+	case !s.FinishedAt.IsZero():
+		return fmt.Sprintf("  %s %s %s %s", doneStr("✔"), name, progressBar(100), mutedStr(formatElapsed(s.Elapsed(now))))
+	case s.Stage != current:
+		return mutedStr(fmt.Sprintf("  · %s", name))
+	}
+
+	line := fmt.Sprintf(
+		"  %s %s %s",
+		spinnerFrames[d.frame%len(spinnerFrames)],
+		name,
+		progressBar(s.Percent),
+	)
+
+	if s.Total > 1 {
+		line += fmt.Sprintf(" %d/%d", s.Done, s.Total)
+	}
+
+	line += " " + mutedStr(formatElapsed(s.Elapsed(now)))
+
+	if s.Detail != "" {
+		line += " " + mutedStr(s.Detail)
+	}
+
+	return line
+}
+
+func progressBar(percent float64) string {
+	filled := int(percent / 100 * progressBarWidth)
+	filled = min(max(filled, 0), progressBarWidth)
+
+	return fmt.Sprintf(
+		"%s%s %3.0f%%",
+		strings.Repeat("█", filled),
+		strings.Repeat("░", progressBarWidth-filled),
+		percent,
+	)
+}
+
+func formatElapsed(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"api/internal/summary/render"
+	"api/internal/summary/shared"
+	"api/internal/summary/storage"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+// startSession starts a new interactive session for topic and walks the user
+// through it.
+func startSession(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	topic string,
+	format render.Format,
+	force bool,
+	languages []string,
+	styleSpec string,
+) {
+	iwf, err := workflow.StartInteractiveWorkflow(
+		ctx,
+		temporalClient,
+		workflow.NewSessionID(),
+		workflow.InteractiveWorkflowParams{
+			InitQuery: topic,
+		},
+	)
+
+	if err != nil {
+		fmt.Println(dangerStr("Failed to create new workflow: %v", err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("Session %s started. If we get disconnected, pick it up again with -resume %s\n\n", iwf.GetID(), iwf.GetID())
+
+	util.LogInfo(
+		"Just a sec! ⏳ I'm quickly assessing your topic to tailor the best follow-up questions for you. 🚀",
+	)
+
+	runSession(ctx, temporalClient, store, iwf.GetID(), format, force, languages, styleSpec)
+}
+
+// resumeSession reattaches to an existing session at whatever step it is in:
+// answering questions, selecting a result or waiting for the summary.
+func resumeSession(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	sessionID string,
+	format render.Format,
+	force bool,
+	languages []string,
+	styleSpec string,
+) {
+	state, err := workflow.QuerySessionState(ctx, temporalClient, sessionID, "")
+
+	if err != nil {
+		fmt.Println(dangerStr("Failed to resume session %s: %v", sessionID, err))
+		os.Exit(1)
+	}
+
+	util.LogInfo(fmt.Sprintf("Welcome back! 👋 Picking up \"%s\" where we left off.", state.InitQuery))
+
+	runSession(ctx, temporalClient, store, sessionID, format, force, languages, styleSpec)
+}
+
+// runSession drives the session until a search result is selected, then
+// summarizes it.
+func runSession(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	sessionID string,
+	format render.Format,
+	force bool,
+	languages []string,
+	styleSpec string,
+) {
+	var selectedResult shared.SearchResult
+
+	for {
+		workflowState, err := workflow.QuerySessionState(ctx, temporalClient, sessionID, "")
+
+		if err != nil {
+			fmt.Println(dangerStr("Could not retrieve workflow state: %v", err))
+			os.Exit(1)
+		}
+
+		if workflowState.Status == workflow.StatusError {
+			fmt.Println(dangerStr("Session %s failed because %s. Please start a new one.", sessionID, workflowState.Error))
+			os.Exit(1)
+		}
+
+		if workflowState.Status == workflow.StatusCanceled {
+			fmt.Println(dangerStr("Session %s was canceled. Please start a new one.", sessionID))
+			os.Exit(1)
+		}
+
+		if workflowState.Status == workflow.StatusCompleted {
+			if workflowState.SearchSelection == nil {
+				fmt.Println(dangerStr("Session %s completed without a selection.", sessionID))
+				os.Exit(1)
+			}
+
+			selectedResult = workflowState.SearchResults[*workflowState.SearchSelection-1]
+			break
+		}
+
+		if workflowState.Status == workflow.StatusAwaitsRefinement {
+			answers := make([]string, 0)
+
+			for i, q := range workflowState.RefinementQuestions {
+				a := util.StringPrompt(questionStr("%s", q))
+				if i == len(workflowState.RefinementQuestions)-1 {
+					fmt.Println("")
+				}
+				answers = append(answers, a)
+			}
+
+			err := workflow.SubmitAnswers(ctx, temporalClient, sessionID, "", answers)
+
+			if workflow.IsInvalidUpdate(err) {
+				fmt.Println(dangerStr("Answers were rejected: %v", err))
+				continue
+			}
+
+			if err != nil {
+				fmt.Println(dangerStr("Failed to submit answers: %v", err))
+				os.Exit(1)
+			}
+
+			util.LogInfo(
+				"The hunt is on! 🕵️‍♀️ I'm busy finding exciting video results tailored just for you. Get ready for some insights! ✨",
+			)
+
+			continue
+		}
+
+		if workflowState.Status == workflow.StatusAwaitsSelection && len(workflowState.SearchResults) > 0 {
+			question := questionStr(
+				"Results are in! 🎬 Which video would you like me to summarize? Select one from the list below: \n",
+			)
+
+			for i, v := range workflowState.SearchResults {
+				question += questionStr("[%d]: %s (%s) \n", i+1, v.Title, v.Origin)
+			}
+
+			answer := util.StringPrompt(question)
+
+			selected, err := strconv.ParseInt(answer, 10, 64)
+
+			if err != nil {
+				fmt.Printf("Invalid input, please enter a number: %v\n", err)
+				continue
+			}
+
+			selectedResult, err = workflow.SubmitSearchSelection(ctx, temporalClient, sessionID, "", selected)
+
+			if workflow.IsInvalidUpdate(err) {
+				fmt.Println(dangerStr("Invalid selection: %v", err))
+				continue
+			}
+
+			if err != nil {
+				fmt.Println(dangerStr("Failed to submit search selection: %v", err))
+				os.Exit(1)
+			}
+
+			break
+		}
+
+		time.Sleep(time.Second * 2)
+	}
+
+	summarizeSelection(ctx, temporalClient, store, sessionID, selectedResult, format, force, languages, styleSpec)
+}
+
+// summarizeSelection waits for the summary of the session's selected result,
+// starting its SummarizeWorkflow unless an earlier run already did.
+func summarizeSelection(
+	ctx context.Context,
+	temporalClient client.Client,
+	store storage.Storage,
+	sessionID string,
+	selected shared.SearchResult,
+	format render.Format,
+	force bool,
+	languages []string,
+	styleSpec string,
+) {
+	summaryID := workflow.SessionSummaryWorkflowID(sessionID)
+
+	_, err := temporalClient.DescribeWorkflowExecution(ctx, summaryID, "")
+
+	var notFound *serviceerror.NotFound
+
+	if err == nil {
+		util.LogInfo(fmt.Sprintf("The summary of %s is already on its way, waiting for it. ⏳", selected.Title))
+		showSummary(ctx, temporalClient, store, temporalClient.GetWorkflow(ctx, summaryID, ""), languages)
+		chatAbout(ctx, temporalClient, selected.Ref())
+		return
+	}
+
+	if !errors.As(err, &notFound) {
+		fmt.Println(dangerStr("Failed to look up the summary workflow: %v", err))
+		os.Exit(1)
+	}
+
+	util.LogInfo(
+		"That's the one! 🎯 Alright, consider it done. \nI'm now going to 📥 grab that video, ✍️ listen to every word to write it all down, and then pull out the most important points for your summary.\nAlmost there! ⬇️ 🎧 ✍️ 💡",
+	)
+
+	run, err := workflow.StartSummarizeWorkflow(
+		ctx,
+		temporalClient,
+		summaryID,
+		workflow.SummarizeWorkflowParams{
+			Source:    selected.Ref(),
+			Title:     selected.Title,
+			Format:    format,
+			Force:     force,
+			Languages: languages,
+			Style:     styleSpec,
+		},
+	)
+
+	if err != nil {
+		fmt.Println(dangerStr("Failed to start summarize workflow: %v", err))
+		os.Exit(1)
+	}
+
+	showSummary(ctx, temporalClient, store, run, languages)
+	chatAbout(ctx, temporalClient, selected.Ref())
+}
+
+func listSessions(ctx context.Context, temporalClient client.Client) {
+	sessions, err := workflow.ListOpenSessions(ctx, temporalClient)
+
+	if err != nil {
+		fmt.Println(dangerStr("Failed to list sessions: %v", err))
+		return
+	}
+
+	if len(sessions) == 0 {
+		util.LogInfo("No open sessions.")
+		return
+	}
+
+	for _, s := range sessions {
+		if s.QueryError != "" {
+			fmt.Printf("%s\t%s\t%s\n", s.ID, s.StartTime.Local().Format(time.DateTime), dangerStr("unavailable: %s", s.QueryError))
+			continue
+		}
+
+		fmt.Printf(
+			"%s\t%s\t%s\t%q\n",
+			s.ID,
+			s.StartTime.Local().Format(time.DateTime),
+			s.State.Status,
+			s.State.InitQuery,
+		)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"api/internal/summary/style"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+type styleOutput struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Builtin     bool   `json:"builtin"`
+}
+
+// runStyles lists the summary styles of the local configuration, which
+// should point at the same styles directory as the worker's.
+func runStyles(ctx context.Context, args []string) error {
+	c := newCLI("styles [flags]")
+
+	if _, err := c.parseArgs(args, 0); err != nil {
+		return err
+	}
+
+	lib, err := style.Load(c.cfg.Styles.Dir)
+
+	if err != nil {
+		return fmt.Errorf("unable to load summary styles: %w", err)
+	}
+
+	styles := lib.List()
+	out := make([]styleOutput, 0, len(styles))
+
+	var text strings.Builder
+	w := tabwriter.NewWriter(&text, 0, 0, 2, ' ', 0)
+
+	for _, s := range styles {
+		out = append(out, styleOutput{s.Name, s.Version, s.Description, s.Builtin})
+
+		origin := "custom"
+
+		if s.Builtin {
+			origin = "built-in"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Ref, origin, s.Description)
+	}
+
+	w.Flush()
+
+	return c.print(out, text.String())
+}
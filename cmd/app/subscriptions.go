@@ -0,0 +1,59 @@
+package main
+
+import (
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+func subscribe(
+	ctx context.Context,
+	temporalClient client.Client,
+	params workflow.SubscriptionParams,
+	interval time.Duration,
+) {
+	subscriptionID, err := workflow.CreateSubscription(ctx, temporalClient, params, interval)
+
+	if err != nil {
+		fmt.Println(dangerStr("Failed to subscribe to %s: %v", params.Channel, err))
+		return
+	}
+
+	util.LogInfo(fmt.Sprintf(
+		"Subscribed! 🔔 I'll check %s every %s and summarize new uploads as they appear.\nUse -unsubscribe %s to stop.",
+		params.Channel,
+		interval,
+		subscriptionID,
+	))
+}
+
+func unsubscribe(ctx context.Context, temporalClient client.Client, subscriptionID string) {
+	if err := workflow.DeleteSubscription(ctx, temporalClient, workflow.SubscriptionID(subscriptionID)); err != nil {
+		fmt.Println(dangerStr("Failed to unsubscribe from %s: %v", subscriptionID, err))
+		return
+	}
+
+	util.LogInfo(fmt.Sprintf("Unsubscribed from %s.", subscriptionID))
+}
+
+func listSubscriptions(ctx context.Context, temporalClient client.Client) {
+	subscriptions, err := workflow.ListSubscriptions(ctx, temporalClient)
+
+	if err != nil {
+		fmt.Println(dangerStr("Failed to list subscriptions: %v", err))
+		return
+	}
+
+	if len(subscriptions) == 0 {
+		util.LogInfo("No subscriptions yet. Add one with -subscribe <channel>.")
+		return
+	}
+
+	for _, s := range subscriptions {
+		fmt.Printf("%s\t%s\tevery %s\tnext check %s\n", s.ID, s.Channel, s.Interval, s.NextPoll.Local().Format(time.DateTime))
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type startChatRequest struct {
+	Source sourceRequest `json:"source"`
+}
+
+type startChatResponse struct {
+	ChatID string `json:"chatId"`
+	RunID  string `json:"runId"`
+}
+
+type askQuestionRequest struct {
+	Question string `json:"question"`
+}
+
+func (s *server) startChat(w http.ResponseWriter, r *http.Request) {
+	var body startChatRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	ref, err := body.Source.ref()
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	run, err := workflow.StartVideoChatWorkflow(
+		r.Context(),
+		s.temporalClient,
+		workflow.NewChatID(),
+		workflow.VideoChatParams{Source: ref},
+	)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to start chat workflow"}, http.StatusInternalServerError)
+		return
+	}
+
+	util.JSONResponse(w, startChatResponse{
+		ChatID: run.GetID(),
+		RunID:  run.GetRunID(),
+	}, http.StatusCreated)
+}
+
+func (s *server) getChat(w http.ResponseWriter, r *http.Request) {
+	state, err := workflow.QueryChatState(r.Context(), s.temporalClient, chi.URLParam(r, "chatID"))
+
+	if err != nil {
+		writeTemporalError(w, err, "chat not found")
+		return
+	}
+
+	util.JSONResponse(w, state, http.StatusOK)
+}
+
+func (s *server) askQuestion(w http.ResponseWriter, r *http.Request) {
+	var body askQuestionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(body.Question) == "" {
+		util.JSONError(w, util.ErrorParam{Error: "question is required"}, http.StatusBadRequest)
+		return
+	}
+
+	turn, err := workflow.AskQuestion(r.Context(), s.temporalClient, chi.URLParam(r, "chatID"), body.Question)
+
+	if err != nil {
+		writeUpdateError(w, err, "chat not found", fmt.Sprintf("no answer because %s", workflow.FailureReason(err)))
+		return
+	}
+
+	util.JSONResponse(w, turn, http.StatusOK)
+}
+
+func (s *server) cancelChat(w http.ResponseWriter, r *http.Request) {
+	s.cancelWorkflow(w, r, chi.URLParam(r, "chatID"), "chat not found")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultFindLimit = 10
+
+type passageResponse struct {
+	VideoID   string  `json:"videoId"`
+	Title     string  `json:"title"`
+	Kind      string  `json:"kind"`
+	Timestamp string  `json:"timestamp"`
+	URL       string  `json:"url"`
+	Text      string  `json:"text"`
+	Score     float64 `json:"score"`
+}
+
+// find returns the passages of every processed video closest in meaning to
+// the q parameter, at most limit of them.
+func (s *server) find(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	if query == "" {
+		util.JSONError(w, util.ErrorParam{Error: "q is required"}, http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultFindLimit
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+
+		if err != nil || n <= 0 {
+			util.JSONError(w, util.ErrorParam{Error: "limit must be a positive number"}, http.StatusBadRequest)
+			return
+		}
+
+		limit = n
+	}
+
+	passages, err := workflow.ExecuteFindWorkflow(r.Context(), s.temporalClient, query, limit)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: fmt.Sprintf("find failed because %s", workflow.FailureReason(err))}, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]passageResponse, 0, len(passages))
+
+	for _, p := range passages {
+		response = append(response, passageResponse{p.VideoID, p.Title, string(p.Kind), p.Timestamp, p.Link, p.Text, p.Score})
+	}
+
+	util.JSONResponse(w, response, http.StatusOK)
+}
@@ -0,0 +1,678 @@
+package main
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/language"
+	"api/internal/summary/render"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"api/internal/summary/style"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+type server struct {
+	temporalClient client.Client
+	store          storage.Storage
+	// styles only validates requested styles, the worker's library is the
+	// one summaries are written with.
+	styles *style.Library
+}
+
+type startSessionRequest struct {
+	Topic string `json:"topic"`
+}
+
+type startSessionResponse struct {
+	SessionID string `json:"sessionId"`
+	RunID     string `json:"runId"`
+}
+
+type sessionResponse struct {
+	SessionID string                            `json:"sessionId"`
+	RunID     string                            `json:"runId"`
+	StartTime time.Time                         `json:"startTime"`
+	State     workflow.InteractiveWorkflowState `json:"state"`
+	Error     string                            `json:"error,omitempty"`
+}
+
+type submitAnswersRequest struct {
+	Answers []string `json:"answers"`
+}
+
+type selectSearchResultRequest struct {
+	Selection int64    `json:"selection"`
+	Format    string   `json:"format"`
+	Force     bool     `json:"force"`
+	Languages []string `json:"languages"`
+	Style     string   `json:"style"`
+}
+
+type selectSearchResultResponse struct {
+	SummaryWorkflowID string `json:"summaryWorkflowId"`
+	URL               string `json:"url"`
+}
+
+type summaryResponse struct {
+	Status      string        `json:"status"`
+	Format      render.Format `json:"format,omitempty"`
+	Summary     string        `json:"summary,omitempty"`
+	DownloadURL string        `json:"downloadUrl,omitempty"`
+	// Error says why the workflow failed.
+	Error string `json:"error,omitempty"`
+}
+
+type sourceRequest struct {
+	// Kind is "url" (default) or "podcast". Files on the worker are only
+	// summarized from the terminal app.
+	Kind     string `json:"kind"`
+	Location string `json:"location"`
+	Episode  string `json:"episode"`
+}
+
+// ref validates the request and turns it into a source.Ref.
+func (req sourceRequest) ref() (source.Ref, error) {
+	ref := source.Ref{
+		Kind:     source.Kind(strings.TrimSpace(req.Kind)),
+		Location: strings.TrimSpace(req.Location),
+		Episode:  strings.TrimSpace(req.Episode),
+	}
+
+	if ref.Kind == "" {
+		ref.Kind = source.KindURL
+	}
+
+	if ref.Location == "" {
+		return ref, errors.New("source location is required")
+	}
+
+	// Anyone who can reach the API could otherwise have any file the worker
+	// can read transcribed into a downloadable summary.
+	if ref.Kind == source.KindFile {
+		return ref, errors.New("file sources are not accepted over HTTP")
+	}
+
+	if err := checkHTTPURL(ref.Location); err != nil {
+		return ref, fmt.Errorf("source location: %w", err)
+	}
+
+	if _, err := source.New(ref); err != nil {
+		return ref, err
+	}
+
+	return ref, nil
+}
+
+// checkHTTPURL accepts only absolute http and https URLs, the ones the worker
+// fetches with yt-dlp or as feeds.
+func checkHTTPURL(s string) error {
+	u, err := url.Parse(s)
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+
+	return nil
+}
+
+type startSummaryRequest struct {
+	Source    sourceRequest `json:"source"`
+	Title     string        `json:"title"`
+	Format    string        `json:"format"`
+	Force     bool          `json:"force"`
+	Languages []string      `json:"languages"`
+	Style     string        `json:"style"`
+}
+
+type startSummaryResponse struct {
+	SummaryID string `json:"summaryId"`
+	RunID     string `json:"runId"`
+}
+
+type startPlaylistRequest struct {
+	URL         string   `json:"url"`
+	Format      string   `json:"format"`
+	Force       bool     `json:"force"`
+	Concurrency int      `json:"concurrency"`
+	MaxVideos   int      `json:"maxVideos"`
+	Languages   []string `json:"languages"`
+	Style       string   `json:"style"`
+}
+
+type startPlaylistResponse struct {
+	PlaylistID string `json:"playlistId"`
+	RunID      string `json:"runId"`
+}
+
+func (s *server) startSession(w http.ResponseWriter, r *http.Request) {
+	var body startSessionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	body.Topic = strings.TrimSpace(body.Topic)
+
+	if body.Topic == "" {
+		util.JSONError(w, util.ErrorParam{Error: "topic is required"}, http.StatusBadRequest)
+		return
+	}
+
+	iwf, err := workflow.StartInteractiveWorkflow(
+		r.Context(),
+		s.temporalClient,
+		workflow.NewSessionID(),
+		workflow.InteractiveWorkflowParams{
+			InitQuery: body.Topic,
+		},
+	)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to start session"}, http.StatusInternalServerError)
+		return
+	}
+
+	util.JSONResponse(w, startSessionResponse{
+		SessionID: iwf.GetID(),
+		RunID:     iwf.GetRunID(),
+	}, http.StatusCreated)
+}
+
+func (s *server) listSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := workflow.ListOpenSessions(r.Context(), s.temporalClient)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to list sessions"}, http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]sessionResponse, 0, len(sessions))
+
+	for _, session := range sessions {
+		resp = append(resp, sessionResponse{
+			SessionID: session.ID,
+			RunID:     session.RunID,
+			StartTime: session.StartTime,
+			State:     session.State,
+			Error:     session.QueryError,
+		})
+	}
+
+	util.JSONResponse(w, resp, http.StatusOK)
+}
+
+func (s *server) getSessionState(w http.ResponseWriter, r *http.Request) {
+	state, ok := s.queryState(w, r)
+
+	if !ok {
+		return
+	}
+
+	util.JSONResponse(w, state, http.StatusOK)
+}
+
+func (s *server) submitAnswers(w http.ResponseWriter, r *http.Request) {
+	var body submitAnswersRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	err := workflow.SubmitAnswers(
+		r.Context(),
+		s.temporalClient,
+		chi.URLParam(r, "sessionID"),
+		"",
+		body.Answers,
+	)
+
+	if err != nil {
+		writeUpdateError(w, err, "session not found", "failed to submit answers")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *server) selectSearchResult(w http.ResponseWriter, r *http.Request) {
+	var body selectSearchResultRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	format, err := render.ParseFormat(body.Format)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	languages, err := language.Parse(body.Languages)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.styles.Resolve(body.Style); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+
+	selected, err := workflow.SubmitSearchSelection(
+		r.Context(),
+		s.temporalClient,
+		sessionID,
+		"",
+		body.Selection,
+	)
+
+	if err != nil {
+		writeUpdateError(w, err, "session not found", "failed to submit selection")
+		return
+	}
+
+	swf, err := workflow.StartSummarizeWorkflow(
+		r.Context(),
+		s.temporalClient,
+		workflow.SessionSummaryWorkflowID(sessionID),
+		workflow.SummarizeWorkflowParams{
+			Source:    selected.Ref(),
+			Title:     selected.Title,
+			Format:    format,
+			Force:     body.Force,
+			Languages: languages,
+			Style:     body.Style,
+		},
+	)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to start summarize workflow"}, http.StatusInternalServerError)
+		return
+	}
+
+	util.JSONResponse(w, selectSearchResultResponse{
+		SummaryWorkflowID: swf.GetID(),
+		URL:               selected.URL,
+	}, http.StatusAccepted)
+}
+
+func (s *server) getSummary(w http.ResponseWriter, r *http.Request) {
+	s.writeOutput(w, r, workflow.SessionSummaryWorkflowID(chi.URLParam(r, "sessionID")))
+}
+
+func (s *server) downloadSummary(w http.ResponseWriter, r *http.Request) {
+	s.downloadOutput(w, r, workflow.SessionSummaryWorkflowID(chi.URLParam(r, "sessionID")))
+}
+
+func (s *server) getSummaryProgress(w http.ResponseWriter, r *http.Request) {
+	s.writeProgress(w, r, workflow.SessionSummaryWorkflowID(chi.URLParam(r, "sessionID")))
+}
+
+func (s *server) startSummary(w http.ResponseWriter, r *http.Request) {
+	var body startSummaryRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	ref, err := body.Source.ref()
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	format, err := render.ParseFormat(body.Format)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	languages, err := language.Parse(body.Languages)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.styles.Resolve(body.Style); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	run, err := workflow.StartSummarizeWorkflow(
+		r.Context(),
+		s.temporalClient,
+		fmt.Sprintf("summarize-workflow-%s", uuid.New().String()),
+		workflow.SummarizeWorkflowParams{
+			Source:    ref,
+			Title:     body.Title,
+			Format:    format,
+			Force:     body.Force,
+			Languages: languages,
+			Style:     body.Style,
+		},
+	)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to start summarize workflow"}, http.StatusInternalServerError)
+		return
+	}
+
+	util.JSONResponse(w, startSummaryResponse{
+		SummaryID: run.GetID(),
+		RunID:     run.GetRunID(),
+	}, http.StatusCreated)
+}
+
+func (s *server) getSummaryByID(w http.ResponseWriter, r *http.Request) {
+	s.writeOutput(w, r, chi.URLParam(r, "summaryID"))
+}
+
+func (s *server) downloadSummaryByID(w http.ResponseWriter, r *http.Request) {
+	s.downloadOutput(w, r, chi.URLParam(r, "summaryID"))
+}
+
+func (s *server) getSummaryProgressByID(w http.ResponseWriter, r *http.Request) {
+	s.writeProgress(w, r, chi.URLParam(r, "summaryID"))
+}
+
+func (s *server) startPlaylist(w http.ResponseWriter, r *http.Request) {
+	var body startPlaylistRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	body.URL = strings.TrimSpace(body.URL)
+
+	if body.URL == "" {
+		util.JSONError(w, util.ErrorParam{Error: "url is required"}, http.StatusBadRequest)
+		return
+	}
+
+	if err := checkHTTPURL(body.URL); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: fmt.Sprintf("url: %v", err)}, http.StatusBadRequest)
+		return
+	}
+
+	format, err := render.ParseFormat(body.Format)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	languages, err := language.Parse(body.Languages)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.styles.Resolve(body.Style); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	run, err := workflow.StartPlaylistSummarizeWorkflow(
+		r.Context(),
+		s.temporalClient,
+		fmt.Sprintf("playlist-workflow-%s", uuid.New().String()),
+		workflow.PlaylistSummarizeWorkflowParams{
+			URL:         body.URL,
+			Format:      format,
+			Force:       body.Force,
+			Concurrency: body.Concurrency,
+			MaxVideos:   body.MaxVideos,
+			Languages:   languages,
+			Style:       body.Style,
+		},
+	)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to start playlist summarize workflow"}, http.StatusInternalServerError)
+		return
+	}
+
+	util.JSONResponse(w, startPlaylistResponse{
+		PlaylistID: run.GetID(),
+		RunID:      run.GetRunID(),
+	}, http.StatusCreated)
+}
+
+func (s *server) getPlaylistIndex(w http.ResponseWriter, r *http.Request) {
+	s.writeOutput(w, r, chi.URLParam(r, "playlistID"))
+}
+
+func (s *server) downloadPlaylistIndex(w http.ResponseWriter, r *http.Request) {
+	s.downloadOutput(w, r, chi.URLParam(r, "playlistID"))
+}
+
+func (s *server) cancelSession(w http.ResponseWriter, r *http.Request) {
+	s.cancelWorkflow(w, r, chi.URLParam(r, "sessionID"), "session not found")
+}
+
+func (s *server) cancelSummary(w http.ResponseWriter, r *http.Request) {
+	s.cancelWorkflow(w, r, chi.URLParam(r, "summaryID"), "summary not found")
+}
+
+func (s *server) cancelPlaylist(w http.ResponseWriter, r *http.Request) {
+	s.cancelWorkflow(w, r, chi.URLParam(r, "playlistID"), "playlist not found")
+}
+
+// cancelWorkflow cancels a running workflow, responding with 409 when it has
+// already closed.
+func (s *server) cancelWorkflow(w http.ResponseWriter, r *http.Request, workflowID string, notFoundMessage string) {
+	_, err := workflow.CancelWorkflow(r.Context(), s.temporalClient, workflowID)
+
+	if errors.Is(err, workflow.ErrNotRunning) {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		writeTemporalError(w, err, notFoundMessage)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeOutput responds with the document produced by a finished summarize or
+// playlist workflow, inlining it for text formats.
+func (s *server) writeOutput(w http.ResponseWriter, r *http.Request, workflowID string) {
+	outputKey, ok := s.workflowOutputKey(w, r, workflowID)
+
+	if !ok {
+		return
+	}
+
+	format, err := render.ParseFormat(path.Ext(outputKey))
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
+	resp := summaryResponse{
+		Status:      "completed",
+		Format:      format,
+		DownloadURL: r.URL.Path + "/download",
+	}
+
+	if r.URL.RawQuery != "" {
+		resp.DownloadURL += "?" + r.URL.RawQuery
+	}
+
+	if format == render.FormatMarkdown || format == render.FormatHTML || format == render.FormatJSON {
+		source, err := storage.ReadAll(r.Context(), s.store, outputKey)
+
+		if err != nil {
+			util.JSONError(w, util.ErrorParam{Error: "failed to read summary"}, http.StatusInternalServerError)
+			return
+		}
+
+		resp.Summary = string(source)
+	}
+
+	util.JSONResponse(w, resp, http.StatusOK)
+}
+
+// writeProgress responds with the progress of a summarize workflow. It also
+// works once the workflow has completed, as long as its history is retained.
+func (s *server) writeProgress(w http.ResponseWriter, r *http.Request, workflowID string) {
+	progress, err := workflow.GetProgress(r.Context(), s.temporalClient, workflowID, "")
+
+	if err != nil {
+		writeTemporalError(w, err, "summary not found")
+		return
+	}
+
+	util.JSONResponse(w, progress, http.StatusOK)
+}
+
+func (s *server) downloadOutput(w http.ResponseWriter, r *http.Request, workflowID string) {
+	outputKey, ok := s.workflowOutputKey(w, r, workflowID)
+
+	if !ok {
+		return
+	}
+
+	format, err := render.ParseFormat(path.Ext(outputKey))
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
+	renderer, err := render.New(format)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
+	source, err := storage.ReadAll(r.Context(), s.store, outputKey)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to read summary"}, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", renderer.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(outputKey)))
+	w.Write(source)
+}
+
+// workflowOutputKey returns the storage key returned by a finished summarize
+// or playlist workflow, or of its translation into the language of the
+// "language" query parameter. While the workflow is running it responds with
+// 202 and reports false, as it does for any error.
+func (s *server) workflowOutputKey(w http.ResponseWriter, r *http.Request, workflowID string) (string, bool) {
+	resp, err := s.temporalClient.DescribeWorkflowExecution(r.Context(), workflowID, "")
+
+	if err != nil {
+		writeTemporalError(w, err, "no summary has been requested for this id")
+		return "", false
+	}
+
+	switch resp.WorkflowExecutionInfo.Status {
+	case enums.WORKFLOW_EXECUTION_STATUS_RUNNING:
+		util.JSONResponse(w, summaryResponse{Status: "running"}, http.StatusAccepted)
+		return "", false
+	}
+
+	var outputKey string
+
+	if err := s.temporalClient.GetWorkflow(r.Context(), workflowID, "").Get(r.Context(), &outputKey); err != nil {
+		util.JSONResponse(w, summaryResponse{
+			Status: strings.ToLower(resp.WorkflowExecutionInfo.Status.String()),
+			Error:  workflow.FailureReason(err),
+		}, http.StatusOK)
+		return "", false
+	}
+
+	if lang := r.URL.Query().Get("language"); lang != "" {
+		code := language.Code(lang)
+
+		if code == "" {
+			util.JSONError(w, util.ErrorParam{Error: fmt.Sprintf("unknown language %q", lang)}, http.StatusBadRequest)
+			return "", false
+		}
+
+		outputKey = activity.LanguageOutputKey(outputKey, code)
+
+		if exists, err := s.store.Exists(r.Context(), outputKey); err != nil || !exists {
+			util.JSONError(w, util.ErrorParam{Error: "the summary was not requested in this language"}, http.StatusNotFound)
+			return "", false
+		}
+	}
+
+	return outputKey, true
+}
+
+func (s *server) queryState(w http.ResponseWriter, r *http.Request) (workflow.InteractiveWorkflowState, bool) {
+	state, err := workflow.QuerySessionState(r.Context(), s.temporalClient, chi.URLParam(r, "sessionID"), "")
+
+	if err != nil {
+		writeTemporalError(w, err, "session not found")
+		return state, false
+	}
+
+	return state, true
+}
+
+func writeTemporalError(w http.ResponseWriter, err error, notFoundMessage string) {
+	var notFound *serviceerror.NotFound
+
+	if errors.As(err, &notFound) {
+		util.JSONError(w, util.ErrorParam{Error: notFoundMessage}, http.StatusNotFound)
+		return
+	}
+
+	util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusInternalServerError)
+}
+
+func writeUpdateError(w http.ResponseWriter, err error, notFoundMessage string, message string) {
+	var appErr *temporal.ApplicationError
+
+	if errors.As(err, &appErr) && appErr.Type() == workflow.ErrTypeInvalidUpdate {
+		util.JSONError(w, util.ErrorParam{Error: appErr.Message()}, http.StatusUnprocessableEntity)
+		return
+	}
+
+	var notFound *serviceerror.NotFound
+
+	if errors.As(err, &notFound) {
+		util.JSONError(w, util.ErrorParam{Error: notFoundMessage}, http.StatusNotFound)
+		return
+	}
+
+	util.JSONError(w, util.ErrorParam{Error: message}, http.StatusInternalServerError)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"api/internal/config"
+	"api/internal/summary/storage"
+	"api/internal/summary/style"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.temporal.io/sdk/client"
+)
+
+func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
+
+	if err != nil {
+		log.Fatalln("Invalid configuration:", err.Error())
+	}
+
+	workflow.Configure(cfg.WorkflowSettings())
+
+	clientOptions, err := cfg.Temporal.ClientOptions()
+
+	if err != nil {
+		log.Fatalln("Invalid Temporal configuration:", err.Error())
+	}
+
+	clientOptions.Logger = util.CustomLogger{}
+
+	temporalClient, err := client.Dial(clientOptions)
+
+	if err != nil {
+		log.Fatalln("Unable to create Temporal Client", err.Error())
+	}
+
+	defer temporalClient.Close()
+
+	store, err := storage.New(context.Background(), cfg.Storage)
+
+	if err != nil {
+		log.Fatalln("Unable to create artifact storage", err.Error())
+	}
+
+	styles, err := style.Load(cfg.Styles.Dir)
+
+	if err != nil {
+		log.Fatalln("Unable to load summary styles", err.Error())
+	}
+
+	s := &server{
+		temporalClient: temporalClient,
+		store:          store,
+		styles:         styles,
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+	r.Use(middleware.Timeout(time.Minute))
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		util.JSONError(w, util.ErrorParam{Error: "route not found"}, http.StatusNotFound)
+	})
+
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		util.JSONError(w, util.ErrorParam{Error: "method not allowed"}, http.StatusMethodNotAllowed)
+	})
+
+	r.Route("/sessions", func(r chi.Router) {
+		r.Post("/", s.startSession)
+		r.Get("/", s.listSessions)
+
+		r.Route("/{sessionID}", func(r chi.Router) {
+			r.Get("/", s.getSessionState)
+			r.Delete("/", s.cancelSession)
+			r.Post("/answers", s.submitAnswers)
+			r.Post("/selection", s.selectSearchResult)
+			r.Get("/summary", s.getSummary)
+			r.Get("/summary/download", s.downloadSummary)
+			r.Get("/summary/progress", s.getSummaryProgress)
+		})
+	})
+
+	r.Get("/styles", s.listStyles)
+	r.Get("/find", s.find)
+
+	r.Route("/summaries", func(r chi.Router) {
+		r.Post("/", s.startSummary)
+
+		r.Route("/{summaryID}", func(r chi.Router) {
+			r.Get("/", s.getSummaryByID)
+			r.Delete("/", s.cancelSummary)
+			r.Get("/download", s.downloadSummaryByID)
+			r.Get("/progress", s.getSummaryProgressByID)
+		})
+	})
+
+	r.Route("/playlists", func(r chi.Router) {
+		r.Post("/", s.startPlaylist)
+
+		r.Route("/{playlistID}", func(r chi.Router) {
+			r.Get("/", s.getPlaylistIndex)
+			r.Delete("/", s.cancelPlaylist)
+			r.Get("/download", s.downloadPlaylistIndex)
+		})
+	})
+
+	r.Route("/chats", func(r chi.Router) {
+		r.Post("/", s.startChat)
+
+		r.Route("/{chatID}", func(r chi.Router) {
+			r.Get("/", s.getChat)
+			r.Delete("/", s.cancelChat)
+			r.Post("/questions", s.askQuestion)
+		})
+	})
+
+	r.Route("/subscriptions", func(r chi.Router) {
+		r.Post("/", s.createSubscription)
+		r.Get("/", s.listSubscriptions)
+		r.Delete("/{subscriptionID}", s.deleteSubscription)
+	})
+
+	log.Printf("Listening on %s", cfg.Server.Addr)
+
+	if err := http.ListenAndServe(cfg.Server.Addr, r); err != nil {
+		log.Fatalln("Server failed", err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"api/internal/util"
+	"net/http"
+)
+
+type styleResponse struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Builtin     bool   `json:"builtin"`
+}
+
+func (s *server) listStyles(w http.ResponseWriter, r *http.Request) {
+	styles := s.styles.List()
+	response := make([]styleResponse, 0, len(styles))
+
+	for _, st := range styles {
+		response = append(response, styleResponse{st.Name, st.Version, st.Description, st.Builtin})
+	}
+
+	util.JSONResponse(w, response, http.StatusOK)
+}
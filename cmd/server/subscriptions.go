@@ -0,0 +1,153 @@
+package main
+
+import (
+	"api/internal/summary/language"
+	"api/internal/summary/render"
+	"api/internal/summary/workflow"
+	"api/internal/util"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.temporal.io/sdk/temporal"
+)
+
+const defaultPollInterval = 6 * time.Hour
+
+type createSubscriptionRequest struct {
+	Channel   string   `json:"channel"`
+	Format    string   `json:"format"`
+	MaxVideos int      `json:"maxVideos"`
+	Languages []string `json:"languages"`
+	Style     string   `json:"style"`
+	// Interval is a Go duration such as "6h", defaultPollInterval if empty.
+	Interval string `json:"interval"`
+}
+
+type subscriptionResponse struct {
+	SubscriptionID string     `json:"subscriptionId"`
+	Channel        string     `json:"channel,omitempty"`
+	Interval       string     `json:"interval,omitempty"`
+	LastPoll       *time.Time `json:"lastPoll,omitempty"`
+	NextPoll       *time.Time `json:"nextPoll,omitempty"`
+}
+
+func (s *server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var body createSubscriptionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "invalid request body"}, http.StatusBadRequest)
+		return
+	}
+
+	body.Channel = strings.TrimSpace(body.Channel)
+
+	if body.Channel == "" {
+		util.JSONError(w, util.ErrorParam{Error: "channel is required"}, http.StatusBadRequest)
+		return
+	}
+
+	format, err := render.ParseFormat(body.Format)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	languages, err := language.Parse(body.Languages)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.styles.Resolve(body.Style); err != nil {
+		util.JSONError(w, util.ErrorParam{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	interval := defaultPollInterval
+
+	if body.Interval != "" {
+		interval, err = time.ParseDuration(body.Interval)
+
+		if err != nil || interval < time.Minute {
+			util.JSONError(w, util.ErrorParam{Error: "interval must be a duration of at least 1m"}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	subscriptionID, err := workflow.CreateSubscription(
+		r.Context(),
+		s.temporalClient,
+		workflow.SubscriptionParams{
+			Channel:   body.Channel,
+			Format:    format,
+			MaxVideos: body.MaxVideos,
+			Languages: languages,
+			Style:     body.Style,
+		},
+		interval,
+	)
+
+	if errors.Is(err, temporal.ErrScheduleAlreadyRunning) {
+		util.JSONError(w, util.ErrorParam{Error: "already subscribed to this channel"}, http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to create subscription"}, http.StatusInternalServerError)
+		return
+	}
+
+	util.JSONResponse(w, subscriptionResponse{
+		SubscriptionID: subscriptionID,
+		Channel:        body.Channel,
+		Interval:       interval.String(),
+	}, http.StatusCreated)
+}
+
+func (s *server) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := workflow.ListSubscriptions(r.Context(), s.temporalClient)
+
+	if err != nil {
+		util.JSONError(w, util.ErrorParam{Error: "failed to list subscriptions"}, http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]subscriptionResponse, 0, len(subscriptions))
+
+	for _, sub := range subscriptions {
+		item := subscriptionResponse{
+			SubscriptionID: sub.ID,
+			Channel:        sub.Channel,
+			Interval:       sub.Interval.String(),
+		}
+
+		if !sub.LastPoll.IsZero() {
+			item.LastPoll = &sub.LastPoll
+		}
+
+		if !sub.NextPoll.IsZero() {
+			item.NextPoll = &sub.NextPoll
+		}
+
+		resp = append(resp, item)
+	}
+
+	util.JSONResponse(w, resp, http.StatusOK)
+}
+
+func (s *server) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	err := workflow.DeleteSubscription(r.Context(), s.temporalClient, chi.URLParam(r, "subscriptionID"))
+
+	if err != nil {
+		writeTemporalError(w, err, "subscription not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
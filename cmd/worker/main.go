@@ -1,12 +1,18 @@
 package main
 
 import (
+	"api/internal/config"
 	"api/internal/summary/activity"
+	"api/internal/summary/index"
+	"api/internal/summary/search"
+	"api/internal/summary/storage"
+	"api/internal/summary/style"
+	"api/internal/summary/transcriber"
 	"api/internal/summary/workflow"
 	"context"
+	"flag"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"github.com/openai/openai-go"
@@ -16,8 +22,10 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
-func RegisterNamespace(ctx context.Context, hostPort, namespace string) error {
-	nsClient, err := client.NewNamespaceClient(client.Options{HostPort: hostPort})
+func RegisterNamespace(ctx context.Context, options client.Options, retentionPeriod time.Duration) error {
+	namespace := options.Namespace
+
+	nsClient, err := client.NewNamespaceClient(options)
 	if err != nil {
 		return fmt.Errorf("unable to create namespace client: %w", err)
 	}
@@ -27,9 +35,7 @@ func RegisterNamespace(ctx context.Context, hostPort, namespace string) error {
 		return nil
 	}
 
-	retention := &durationpb.Duration{
-		Seconds: int64(30) * int64(24*time.Hour) / int64(time.Second),
-	}
+	retention := durationpb.New(retentionPeriod)
 
 	req := &workflowservice.RegisterNamespaceRequest{
 		Namespace:                        namespace,
@@ -44,21 +50,30 @@ func RegisterNamespace(ctx context.Context, hostPort, namespace string) error {
 }
 
 func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
+
+	if err != nil {
+		log.Fatalln("Invalid configuration:", err.Error())
+	}
+
+	workflow.Configure(cfg.WorkflowSettings())
+
+	clientOptions, err := cfg.Temporal.ClientOptions()
+
+	if err != nil {
+		log.Fatalln("Invalid Temporal configuration:", err.Error())
+	}
 
-	err := RegisterNamespace(
-		context.Background(),
-		client.DefaultHostPort,
-		os.Getenv("TEMPORAL_SUMMARIZE_NAMESPACE"),
-	)
+	err = RegisterNamespace(context.Background(), clientOptions, cfg.Temporal.NamespaceRetention)
 
 	if err != nil {
 		log.Fatalln("Failed to register Temporal namespace:", err.Error())
 	}
 
-	temporalClient, err := client.Dial(client.Options{
-		HostPort:  client.DefaultHostPort,
-		Namespace: os.Getenv("TEMPORAL_SUMMARIZE_NAMESPACE"),
-	})
+	temporalClient, err := client.Dial(clientOptions)
 
 	if err != nil {
 		log.Fatalln("Unable to create Temporal Client", err.Error())
@@ -66,23 +81,85 @@ func main() {
 
 	defer temporalClient.Close()
 
+	err = workflow.ScheduleCleanup(context.Background(), temporalClient, cfg.RetentionPolicy(), cfg.Retention.CleanupInterval)
+
+	if err != nil {
+		log.Fatalln("Failed to schedule artifact cleanup:", err.Error())
+	}
+
 	openAPIClient := openai.NewClient()
 
-	w := worker.New(temporalClient, os.Getenv("TEMPORAL_SUMMARIZE_QUEUE_NAME"), worker.Options{})
+	w := worker.New(temporalClient, cfg.Temporal.TaskQueue, worker.Options{})
 	/* Register Workflows */
 	w.RegisterWorkflow(workflow.SummarizeWorkflow)
 	w.RegisterWorkflow(workflow.InteractiveWorkflow)
+	w.RegisterWorkflow(workflow.PlaylistSummarizeWorkflow)
+	w.RegisterWorkflow(workflow.SubscriptionPollWorkflow)
+	w.RegisterWorkflow(workflow.SearchWorkflow)
+	w.RegisterWorkflow(workflow.CleanupWorkflow)
+	w.RegisterWorkflow(workflow.VideoChatWorkflow)
+	w.RegisterWorkflow(workflow.FindWorkflow)
+	w.RegisterWorkflow(workflow.IndexLibraryWorkflow)
+	w.RegisterWorkflow(workflow.IndexVideoWorkflow)
 
 	/* Register Activities */
-	w.RegisterActivity(activity.RetrieveAudio)
+	store, err := storage.New(context.Background(), cfg.Storage)
+
+	if err != nil {
+		log.Fatalln("Unable to create artifact storage", err.Error())
+	}
+
+	artifactActivities := activity.NewArtifactActivities(store)
+	w.RegisterActivity(artifactActivities)
+
+	audioTranscriber, err := transcriber.New(cfg.Transcriber, openAPIClient)
 
-	audioProcessingActivities := activity.NewAudioProcessActivities(openAPIClient)
+	if err != nil {
+		log.Fatalln("Unable to create transcriber", err.Error())
+	}
+
+	styles, err := style.Load(cfg.Styles.Dir)
+
+	if err != nil {
+		log.Fatalln("Unable to load summary styles", err.Error())
+	}
+
+	audioProcessingActivities := activity.NewAudioProcessActivities(openAPIClient, audioTranscriber, store, cfg.Models.Summary, styles)
 	w.RegisterActivity(audioProcessingActivities)
 
-	w.RegisterActivity(activity.CreateSummaryOutputFile)
-	w.RegisterActivity(activity.OutputSummaryToFile)
-	w.RegisterActivity(activity.Refine)
-	w.RegisterActivity(activity.Search)
+	searchProvider, err := search.New(cfg.Search, store)
+
+	if err != nil {
+		log.Fatalln("Unable to create search provider", err.Error())
+	}
+
+	searchActivities := activity.NewSearchActivities(searchProvider, cfg.Models.Agent)
+	w.RegisterActivity(searchActivities)
+
+	w.RegisterActivity(activity.ListPlaylistVideos)
+	w.RegisterActivity(activity.ListChannelUploads)
+	w.RegisterActivity(activity.ResolveSource)
+
+	// The index is a local file, so its activities get a task queue of their
+	// own that only this worker serves.
+	if cfg.Index.Serve {
+		semanticIndex, err := index.Open(cfg.Index.Path)
+
+		if err != nil {
+			log.Fatalln("Unable to open semantic index", err.Error())
+		}
+
+		defer semanticIndex.Close()
+
+		iw := worker.New(temporalClient, cfg.Index.TaskQueue, worker.Options{})
+		iw.RegisterActivity(activity.NewIndexActivities(openAPIClient, store, semanticIndex, cfg.Models.Embedding))
+
+		if err := iw.Start(); err != nil {
+			log.Fatalln("Index worker failed to start", err)
+		}
+
+		defer iw.Stop()
+	}
 
 	if err := w.Run(worker.InterruptCh()); err != nil {
 		log.Fatalln("Worker failed to start", err)
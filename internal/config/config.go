@@ -0,0 +1,239 @@
+package config
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/search"
+	"api/internal/summary/storage"
+	"api/internal/summary/transcriber"
+	"api/internal/summary/workflow"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/openai/openai-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration shared by the app, server and worker. It is
+// built from defaults, a YAML file, environment variables and flags, each
+// overriding the previous one.
+type Config struct {
+	Temporal    TemporalConfig     `yaml:"temporal"`
+	Retention   RetentionConfig    `yaml:"retention"`
+	Storage     storage.Config     `yaml:"storage"`
+	Transcriber transcriber.Config `yaml:"transcriber"`
+	Search      search.Config      `yaml:"search"`
+	Models      ModelsConfig       `yaml:"models"`
+	Styles      StylesConfig       `yaml:"styles"`
+	Index       IndexConfig        `yaml:"index"`
+	Timeouts    TimeoutsConfig     `yaml:"timeouts"`
+	Server      ServerConfig       `yaml:"server"`
+}
+
+// RetentionConfig is how long stored artifacts are kept, next to
+// TemporalConfig.NamespaceRetention for workflow histories. Zero keeps an
+// artifact forever.
+type RetentionConfig struct {
+	Audio      time.Duration `yaml:"audio"`
+	Transcript time.Duration `yaml:"transcript"`
+	Summary    time.Duration `yaml:"summary"`
+	// Output is the retention of rendered summaries and playlist indexes.
+	Output time.Duration `yaml:"output"`
+	// CleanupInterval is how often the worker's schedule removes expired
+	// artifacts. Zero removes the schedule.
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
+type ModelsConfig struct {
+	// Summary is the chat model that writes summaries.
+	Summary string `yaml:"summary"`
+	// Agent is the chat model the refine and search agents run on.
+	Agent string `yaml:"agent"`
+	// Embedding is the model transcripts and summaries are embedded with
+	// for the semantic index. Changing it re-embeds the library the next
+	// time it is indexed.
+	Embedding string `yaml:"embedding"`
+}
+
+type StylesConfig struct {
+	// Dir holds *.yaml summary style files that add to or replace the
+	// built-in styles, see the style package.
+	Dir string `yaml:"dir"`
+}
+
+type IndexConfig struct {
+	// Path is the SQLite file of the semantic index, on the worker's disk.
+	Path string `yaml:"path"`
+	// TaskQueue is where the index activities run. Exactly one worker may
+	// serve it, as every worker has an index of its own.
+	TaskQueue string `yaml:"task_queue"`
+	// Serve makes the worker keep the index and serve TaskQueue. It is off by
+	// default, so adding a worker never splits the index.
+	Serve bool `yaml:"serve"`
+}
+
+type TimeoutsConfig struct {
+	// Activity is the StartToClose timeout of most activities.
+	Activity time.Duration `yaml:"activity"`
+	// Transcription is the StartToClose timeout of each audio chunk
+	// transcription.
+	Transcription time.Duration `yaml:"transcription"`
+	// Download is the StartToClose timeout of each audio download attempt.
+	Download time.Duration `yaml:"download"`
+}
+
+type ServerConfig struct {
+	Addr string `yaml:"addr"`
+}
+
+func Default() *Config {
+	dir, _ := os.Getwd()
+
+	return &Config{
+		Temporal: TemporalConfig{
+			Address:            "localhost:7233",
+			Namespace:          "summarize",
+			TaskQueue:          "summarize",
+			NamespaceRetention: 30 * 24 * time.Hour,
+		},
+		Retention: RetentionConfig{
+			Audio:           24 * time.Hour,
+			Transcript:      90 * 24 * time.Hour,
+			CleanupInterval: 24 * time.Hour,
+		},
+		Storage: storage.Config{
+			Backend:  storage.BackendLocal,
+			LocalDir: filepath.Join(dir, "output"),
+			S3: storage.S3Config{
+				UseSSL: true,
+			},
+		},
+		Transcriber: transcriber.Config{
+			Backend: transcriber.BackendOpenAI,
+		},
+		Search: search.Config{
+			Backends:   []search.Backend{search.BackendYoutube},
+			MaxResults: 5,
+		},
+		Models: ModelsConfig{
+			Summary:   openai.ChatModelGPT4o,
+			Agent:     openai.ChatModelGPT4oMini,
+			Embedding: openai.EmbeddingModelTextEmbedding3Small,
+		},
+		Index: IndexConfig{
+			Path:      filepath.Join(dir, "index.db"),
+			TaskQueue: "summarize-index",
+		},
+		Timeouts: TimeoutsConfig{
+			Activity:      5 * time.Minute,
+			Transcription: 15 * time.Minute,
+			Download:      time.Hour,
+		},
+		Server: ServerConfig{
+			Addr: ":8080",
+		},
+	}
+}
+
+// loadFile overrides cfg with the values present in a YAML file.
+func (cfg *Config) loadFile(filePath string) error {
+	content, err := os.ReadFile(filePath)
+
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// Validate reports every invalid value at once.
+func (cfg *Config) Validate() error {
+	errs := make([]error, 0)
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Temporal.Address != "", "temporal address is required")
+	check(cfg.Temporal.Namespace != "", "temporal namespace is required")
+	check(cfg.Temporal.TaskQueue != "", "temporal task queue is required")
+	check(cfg.Temporal.NamespaceRetention >= 24*time.Hour, "temporal namespace retention must be at least 24h")
+	check(cfg.Retention.Audio >= 0, "audio retention must not be negative")
+	check(cfg.Retention.Transcript >= 0, "transcript retention must not be negative")
+	check(cfg.Retention.Summary >= 0, "summary retention must not be negative")
+	check(cfg.Retention.Output >= 0, "output retention must not be negative")
+	check(cfg.Retention.CleanupInterval >= 0, "cleanup interval must not be negative")
+	check((cfg.Temporal.TLS.CertFile == "") == (cfg.Temporal.TLS.KeyFile == ""), "temporal TLS cert and key files must be set together")
+
+	switch cfg.Storage.Backend {
+	case storage.BackendLocal:
+		check(cfg.Storage.LocalDir != "", "storage local dir is required")
+	case storage.BackendS3:
+		check(cfg.Storage.S3.Endpoint != "", "S3 endpoint is required")
+		check(cfg.Storage.S3.Bucket != "", "S3 bucket is required")
+	default:
+		check(false, "unknown storage backend %q", cfg.Storage.Backend)
+	}
+
+	switch cfg.Transcriber.Backend {
+	case transcriber.BackendOpenAI, transcriber.BackendFasterWhisper:
+	case transcriber.BackendWhisperCpp:
+		check(cfg.Transcriber.Model != "", "transcriber model is required for whisper-cpp")
+	default:
+		check(false, "unknown transcriber backend %q", cfg.Transcriber.Backend)
+	}
+
+	check(len(cfg.Search.Backends) > 0, "at least one search provider is required")
+
+	for _, backend := range cfg.Search.Backends {
+		check(
+			slices.Contains([]search.Backend{search.BackendYoutube, search.BackendPodcasts, search.BackendLibrary}, backend),
+			"unknown search provider %q",
+			backend,
+		)
+	}
+
+	check(cfg.Search.MaxResults > 0, "search max results must be positive")
+	check(cfg.Models.Summary != "", "summary model is required")
+	check(cfg.Models.Agent != "", "agent model is required")
+	check(cfg.Models.Embedding != "", "embedding model is required")
+	check(cfg.Index.Path != "", "index path is required")
+	check(cfg.Index.TaskQueue != "", "index task queue is required")
+	check(cfg.Index.TaskQueue != cfg.Temporal.TaskQueue, "index task queue must differ from the temporal task queue")
+	check(cfg.Timeouts.Activity > 0, "activity timeout must be positive")
+	check(cfg.Timeouts.Transcription > 0, "transcription timeout must be positive")
+	check(cfg.Timeouts.Download > 0, "download timeout must be positive")
+	check(cfg.Server.Addr != "", "server addr is required")
+
+	return errors.Join(errs...)
+}
+
+// RetentionPolicy returns the policy to pass to the cleanup workflow.
+func (cfg *Config) RetentionPolicy() activity.RetentionPolicy {
+	return activity.RetentionPolicy{
+		Audio:      cfg.Retention.Audio,
+		Transcript: cfg.Retention.Transcript,
+		Summary:    cfg.Retention.Summary,
+		Output:     cfg.Retention.Output,
+	}
+}
+
+// WorkflowSettings returns the settings to pass to workflow.Configure.
+func (cfg *Config) WorkflowSettings() workflow.Settings {
+	return workflow.Settings{
+		TaskQueue:            cfg.Temporal.TaskQueue,
+		IndexTaskQueue:       cfg.Index.TaskQueue,
+		ActivityTimeout:      cfg.Timeouts.Activity,
+		TranscriptionTimeout: cfg.Timeouts.Transcription,
+		DownloadTimeout:      cfg.Timeouts.Download,
+	}
+}
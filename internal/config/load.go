@@ -0,0 +1,209 @@
+package config
+
+import (
+	"api/internal/summary/search"
+	"api/internal/summary/storage"
+	"api/internal/summary/transcriber"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting maps an environment variable and, unless flagName is empty, a
+// flag onto a configuration value. Secrets have no flag so they never show
+// up in process listings.
+type setting struct {
+	env      string
+	flagName string
+	usage    string
+	set      setter
+}
+
+type setter struct {
+	apply  func(cfg *Config, value string) error
+	isBool bool
+}
+
+var settings = []setting{
+	{"TEMPORAL_ADDRESS", "temporal-address", "Temporal frontend host:port", stringValue(func(c *Config) *string { return &c.Temporal.Address })},
+	{"TEMPORAL_SUMMARIZE_NAMESPACE", "temporal-namespace", "Temporal namespace", stringValue(func(c *Config) *string { return &c.Temporal.Namespace })},
+	{"TEMPORAL_SUMMARIZE_QUEUE_NAME", "temporal-task-queue", "Temporal task queue", stringValue(func(c *Config) *string { return &c.Temporal.TaskQueue })},
+	{"TEMPORAL_NAMESPACE_RETENTION", "temporal-namespace-retention", "retention of a namespace registered by the worker", durationValue(func(c *Config) *time.Duration { return &c.Temporal.NamespaceRetention })},
+	{"AUDIO_RETENTION", "audio-retention", "how long downloaded audio is kept, 0 forever", durationValue(func(c *Config) *time.Duration { return &c.Retention.Audio })},
+	{"TRANSCRIPT_RETENTION", "transcript-retention", "how long transcripts are kept, 0 forever", durationValue(func(c *Config) *time.Duration { return &c.Retention.Transcript })},
+	{"SUMMARY_RETENTION", "summary-retention", "how long cached summaries are kept, 0 forever", durationValue(func(c *Config) *time.Duration { return &c.Retention.Summary })},
+	{"OUTPUT_RETENTION", "output-retention", "how long rendered summaries are kept, 0 forever", durationValue(func(c *Config) *time.Duration { return &c.Retention.Output })},
+	{"CLEANUP_INTERVAL", "cleanup-interval", "how often expired artifacts are removed, 0 to never", durationValue(func(c *Config) *time.Duration { return &c.Retention.CleanupInterval })},
+	{"TEMPORAL_API_KEY", "", "", stringValue(func(c *Config) *string { return &c.Temporal.APIKey })},
+	{"TEMPORAL_TLS", "temporal-tls", "connect to Temporal over TLS", boolValue(func(c *Config) *bool { return &c.Temporal.TLS.Enabled })},
+	{"TEMPORAL_TLS_CERT", "temporal-tls-cert", "client certificate file for Temporal mTLS", stringValue(func(c *Config) *string { return &c.Temporal.TLS.CertFile })},
+	{"TEMPORAL_TLS_KEY", "temporal-tls-key", "client key file for Temporal mTLS", stringValue(func(c *Config) *string { return &c.Temporal.TLS.KeyFile })},
+	{"TEMPORAL_TLS_CA", "temporal-tls-ca", "CA certificate file of the Temporal server", stringValue(func(c *Config) *string { return &c.Temporal.TLS.CAFile })},
+	{"TEMPORAL_TLS_SERVER_NAME", "temporal-tls-server-name", "server name to verify the Temporal certificate against", stringValue(func(c *Config) *string { return &c.Temporal.TLS.ServerName })},
+	{"STORAGE_BACKEND", "storage-backend", "artifact storage backend: local or s3", setter{apply: func(c *Config, v string) error {
+		c.Storage.Backend = storage.Backend(v)
+		return nil
+	}}},
+	{"STORAGE_LOCAL_DIR", "storage-dir", "root directory of the local artifact storage", stringValue(func(c *Config) *string { return &c.Storage.LocalDir })},
+	{"S3_ENDPOINT", "s3-endpoint", "S3-compatible endpoint", stringValue(func(c *Config) *string { return &c.Storage.S3.Endpoint })},
+	{"S3_BUCKET", "s3-bucket", "S3 bucket", stringValue(func(c *Config) *string { return &c.Storage.S3.Bucket })},
+	{"S3_REGION", "s3-region", "S3 region", stringValue(func(c *Config) *string { return &c.Storage.S3.Region })},
+	{"S3_ACCESS_KEY_ID", "", "", stringValue(func(c *Config) *string { return &c.Storage.S3.AccessKeyID })},
+	{"S3_SECRET_ACCESS_KEY", "", "", stringValue(func(c *Config) *string { return &c.Storage.S3.SecretAccessKey })},
+	{"S3_USE_SSL", "s3-use-ssl", "use HTTPS for the S3 endpoint", boolValue(func(c *Config) *bool { return &c.Storage.S3.UseSSL })},
+	{"TRANSCRIBER_BACKEND", "transcriber-backend", "transcriber: openai, whisper-cpp or faster-whisper", setter{apply: func(c *Config, v string) error {
+		c.Transcriber.Backend = transcriber.Backend(v)
+		return nil
+	}}},
+	{"TRANSCRIBER_BINARY", "transcriber-binary", "executable of a local transcriber", stringValue(func(c *Config) *string { return &c.Transcriber.Binary })},
+	{"TRANSCRIBER_MODEL", "transcriber-model", "transcription model name or file", stringValue(func(c *Config) *string { return &c.Transcriber.Model })},
+	{"SEARCH_PROVIDERS", "search-providers", "comma-separated search providers: youtube, podcasts, library", setter{apply: func(c *Config, v string) error {
+		c.Search.Backends = make([]search.Backend, 0)
+
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.Search.Backends = append(c.Search.Backends, search.Backend(name))
+			}
+		}
+
+		return nil
+	}}},
+	{"SEARCH_MAX_RESULTS", "search-max-results", "results contributed by each search provider", intValue(func(c *Config) *int { return &c.Search.MaxResults })},
+	{"SUMMARY_MODEL", "summary-model", "chat model that writes summaries", stringValue(func(c *Config) *string { return &c.Models.Summary })},
+	{"AGENT_MODEL", "agent-model", "chat model of the refine and search agents", stringValue(func(c *Config) *string { return &c.Models.Agent })},
+	{"EMBEDDING_MODEL", "embedding-model", "model that embeds transcripts and summaries for find", stringValue(func(c *Config) *string { return &c.Models.Embedding })},
+	{"STYLES_DIR", "styles-dir", "directory of summary style files adding to the built-in ones", stringValue(func(c *Config) *string { return &c.Styles.Dir })},
+	{"INDEX_PATH", "index-path", "SQLite file of the semantic index", stringValue(func(c *Config) *string { return &c.Index.Path })},
+	{"INDEX_TASK_QUEUE", "index-task-queue", "task queue of the worker that keeps the semantic index", stringValue(func(c *Config) *string { return &c.Index.TaskQueue })},
+	{"INDEX_WORKER", "index-worker", "keep the semantic index on this worker, set on exactly one worker", boolValue(func(c *Config) *bool { return &c.Index.Serve })},
+	{"ACTIVITY_TIMEOUT", "activity-timeout", "StartToClose timeout of most activities", durationValue(func(c *Config) *time.Duration { return &c.Timeouts.Activity })},
+	{"TRANSCRIPTION_TIMEOUT", "transcription-timeout", "StartToClose timeout of each chunk transcription", durationValue(func(c *Config) *time.Duration { return &c.Timeouts.Transcription })},
+	{"DOWNLOAD_TIMEOUT", "download-timeout", "StartToClose timeout of each audio download attempt", durationValue(func(c *Config) *time.Duration { return &c.Timeouts.Download })},
+	{"SERVER_ADDR", "server-addr", "address the HTTP server listens on", stringValue(func(c *Config) *string { return &c.Server.Addr })},
+}
+
+// Flags are the configuration flags registered on a flag set.
+type Flags struct {
+	fs         *flag.FlagSet
+	configFile *string
+	values     map[string]*flagValue
+}
+
+// flagValue keeps the raw flag value so it is only converted, and only
+// overrides the environment, when the flag was actually set.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// RegisterFlags adds -config and a flag for every non-secret setting to fs.
+// Call Load once fs has been parsed.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		fs:         fs,
+		configFile: fs.String("config", "", "YAML config file, defaults to $CONFIG_FILE"),
+		values:     make(map[string]*flagValue),
+	}
+
+	for _, s := range settings {
+		if s.flagName != "" {
+			f.values[s.flagName] = &flagValue{isBool: s.set.isBool}
+			fs.Var(f.values[s.flagName], s.flagName, fmt.Sprintf("%s (env %s)", s.usage, s.env))
+		}
+	}
+
+	return f
+}
+
+// Load applies the config file, the environment and the flags that were set
+// on top of the defaults and validates the result.
+func (f *Flags) Load() (*Config, error) {
+	cfg := Default()
+
+	configFile := *f.configFile
+
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+
+	if configFile != "" {
+		if err := cfg.loadFile(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok && v != "" {
+			if err := s.set.apply(cfg, v); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", s.env, err)
+			}
+		}
+	}
+
+	set := make(map[string]bool)
+
+	f.fs.Visit(func(fl *flag.Flag) {
+		set[fl.Name] = true
+	})
+
+	for _, s := range settings {
+		if s.flagName != "" && set[s.flagName] {
+			if err := s.set.apply(cfg, f.values[s.flagName].value); err != nil {
+				return nil, fmt.Errorf("invalid -%s: %w", s.flagName, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func stringValue(field func(*Config) *string) setter {
+	return setter{apply: func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}}
+}
+
+func boolValue(field func(*Config) *bool) setter {
+	return setter{isBool: true, apply: func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		*field(c) = b
+		return err
+	}}
+}
+
+func intValue(field func(*Config) *int) setter {
+	return setter{apply: func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		*field(c) = n
+		return err
+	}}
+}
+
+func durationValue(field func(*Config) *time.Duration) setter {
+	return setter{apply: func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		*field(c) = d
+		return err
+	}}
+}
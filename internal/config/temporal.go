@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"go.temporal.io/sdk/client"
+)
+
+type TemporalConfig struct {
+	Address   string `yaml:"address"`
+	Namespace string `yaml:"namespace"`
+	TaskQueue string `yaml:"task_queue"`
+	// NamespaceRetention is how long closed workflows are kept in the
+	// namespace the worker registers when it does not exist yet.
+	NamespaceRetention time.Duration `yaml:"namespace_retention"`
+	// APIKey authenticates against Temporal Cloud and implies TLS.
+	APIKey string    `yaml:"api_key"`
+	TLS    TLSConfig `yaml:"tls"`
+}
+
+type TLSConfig struct {
+	// Enabled turns on TLS with the system roots when no files are given.
+	Enabled bool `yaml:"enabled"`
+	// CertFile and KeyFile hold the client certificate for mTLS.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// CAFile holds the certificate authority of the server, for clusters
+	// with private certificates.
+	CAFile             string `yaml:"ca_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// ClientOptions returns the options to dial or create clients for the
+// configured cluster.
+func (c TemporalConfig) ClientOptions() (client.Options, error) {
+	tlsConfig, err := c.TLS.load(c.APIKey != "")
+
+	if err != nil {
+		return client.Options{}, err
+	}
+
+	opts := client.Options{
+		HostPort:  c.Address,
+		Namespace: c.Namespace,
+		ConnectionOptions: client.ConnectionOptions{
+			TLS: tlsConfig,
+		},
+	}
+
+	if c.APIKey != "" {
+		opts.Credentials = client.NewAPIKeyStaticCredentials(c.APIKey)
+	}
+
+	return opts, nil
+}
+
+// load returns nil, for a plain text connection, unless TLS is enabled,
+// required by an API key or implied by a certificate, CA or server name.
+func (t TLSConfig) load(required bool) (*tls.Config, error) {
+	if !t.Enabled && !required && t.CertFile == "" && t.CAFile == "" && t.ServerName == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to load temporal TLS certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CAFile != "" {
+		ca, err := os.ReadFile(t.CAFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read temporal TLS CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,41 @@
+package activity
+
+import (
+	"api/internal/summary/storage"
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// ArtifactActivities are the activities that read or write artifacts such as
+// audio, transcripts and summaries. Artifacts are passed between activities
+// as storage keys, so activities can run on different machines.
+type ArtifactActivities struct {
+	store storage.Storage
+}
+
+func NewArtifactActivities(store storage.Storage) *ArtifactActivities {
+	return &ArtifactActivities{
+		store,
+	}
+}
+
+// downloadToTemp copies an object into a new temporary directory for tools
+// that need a local file. The returned cleanup func removes the directory.
+func downloadToTemp(ctx context.Context, store storage.Storage, key string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "artifact-*")
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	cleanup := func() { os.RemoveAll(dir) }
+	filePath := filepath.Join(dir, filepath.Base(key))
+
+	if err := storage.Download(ctx, store, key, filePath); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return filePath, cleanup, nil
+}
@@ -0,0 +1,155 @@
+package activity
+
+import (
+	"api/internal/summary/cache"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"api/internal/summary/transcriber"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// CachedArtifacts holds the storage keys of the artifacts already cached for
+// a video. Missing artifacts have an empty key.
+type CachedArtifacts struct {
+	AudioKey      string
+	TranscriptKey string
+	// SummaryKey is the summary in the style looked up.
+	SummaryKey string
+	// Language is the spoken language recorded in the cached metadata.
+	Language string
+}
+
+// LookupCache finds the cached artifacts of a video, with the summary in the
+// style given by its style.Ref.CacheName.
+func (aa *ArtifactActivities) LookupCache(ctx context.Context, videoID string, styleName string) (CachedArtifacts, error) {
+	var artifacts CachedArtifacts
+
+	for artifact, key := range map[cache.Artifact]*string{
+		cache.ArtifactAudio:                  &artifacts.AudioKey,
+		cache.ArtifactTranscript:             &artifacts.TranscriptKey,
+		cache.SummaryArtifact(styleName, ""): &artifacts.SummaryKey,
+	} {
+		exists, err := aa.store.Exists(ctx, cache.Key(videoID, artifact))
+
+		if err != nil {
+			return artifacts, err
+		}
+
+		if exists {
+			*key = cache.Key(videoID, artifact)
+		}
+	}
+
+	content, err := storage.ReadAll(ctx, aa.store, cache.Key(videoID, cache.ArtifactMetadata))
+
+	if errors.Is(err, storage.ErrNotFound) {
+		return artifacts, nil
+	}
+
+	if err != nil {
+		return artifacts, err
+	}
+
+	var item source.Item
+
+	if err := json.Unmarshal(content, &item); err == nil {
+		artifacts.Language = item.Language
+	}
+
+	return artifacts, nil
+}
+
+func (aa *ArtifactActivities) CacheTranscript(
+	ctx context.Context,
+	videoID string,
+	transcript transcriber.Transcript,
+) error {
+	content, err := json.Marshal(transcript)
+
+	if err != nil {
+		return err
+	}
+
+	return aa.store.Put(ctx, cache.Key(videoID, cache.ArtifactTranscript), bytes.NewReader(content))
+}
+
+func (aa *ArtifactActivities) LoadCachedTranscript(ctx context.Context, videoID string) (transcriber.Transcript, error) {
+	return loadTranscript(ctx, aa.store, videoID)
+}
+
+func loadTranscript(ctx context.Context, store storage.Storage, videoID string) (transcriber.Transcript, error) {
+	var transcript transcriber.Transcript
+
+	content, err := storage.ReadAll(ctx, store, cache.Key(videoID, cache.ArtifactTranscript))
+
+	if err != nil {
+		return transcript, err
+	}
+
+	err = json.Unmarshal(content, &transcript)
+
+	return transcript, err
+}
+
+func (aa *ArtifactActivities) CacheSummary(ctx context.Context, videoID string, styleName string, summary string) error {
+	return aa.store.Put(ctx, cache.Key(videoID, cache.SummaryArtifact(styleName, "")), strings.NewReader(summary))
+}
+
+// CacheMetadata records the source and title of a cached summary, so the
+// library search can find it.
+func (aa *ArtifactActivities) CacheMetadata(ctx context.Context, videoID string, item source.Item) error {
+	content, err := json.Marshal(item)
+
+	if err != nil {
+		return err
+	}
+
+	return aa.store.Put(ctx, cache.Key(videoID, cache.ArtifactMetadata), bytes.NewReader(content))
+}
+
+// CacheTranslation stores the translation of the cached summary into a
+// language.
+func (aa *ArtifactActivities) CacheTranslation(
+	ctx context.Context,
+	videoID string,
+	styleName string,
+	language string,
+	translation string,
+) error {
+	return aa.store.Put(ctx, cache.Key(videoID, cache.SummaryArtifact(styleName, language)), strings.NewReader(translation))
+}
+
+// LoadCachedTranslation returns the cached translation of the summary into a
+// language, or "" when there is none.
+func (aa *ArtifactActivities) LoadCachedTranslation(
+	ctx context.Context,
+	videoID string,
+	styleName string,
+	language string,
+) (string, error) {
+	content, err := storage.ReadAll(ctx, aa.store, cache.Key(videoID, cache.SummaryArtifact(styleName, language)))
+
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func (aa *ArtifactActivities) LoadCachedSummary(ctx context.Context, videoID string, styleName string) (string, error) {
+	content, err := storage.ReadAll(ctx, aa.store, cache.Key(videoID, cache.SummaryArtifact(styleName, "")))
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
@@ -0,0 +1,181 @@
+package activity
+
+import (
+	"api/internal/summary/transcriber"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// ChatHistoryTurns is how many earlier turns of a chat are sent along with
+// a question, enough for follow-ups without growing every prompt.
+const ChatHistoryTurns = 10
+
+// ChatTurn is one question about a video and its answer.
+type ChatTurn struct {
+	Question  string
+	Answer    string
+	Citations []Citation
+	AskedAt   time.Time
+}
+
+// Citation is a moment of the video an answer refers to.
+type Citation struct {
+	// Timestamp is the [hh:mm:ss] marker in the answer, without brackets.
+	Timestamp string
+	// Offset is the moment in seconds from the start of the video.
+	Offset int
+	// URL opens the video at that moment, empty for sources without deep
+	// links.
+	URL string
+}
+
+type ChatQuestion struct {
+	VideoID string
+	Title   string
+	URL     string
+	// History holds the latest turns of the chat, oldest first.
+	History  []ChatTurn
+	Question string
+}
+
+type ChatAnswer struct {
+	Answer    string
+	Citations []Citation
+}
+
+// AnswerQuestion answers a question about a video from its cached
+// transcript, citing the moments the answer is based on. Transcripts too
+// long for one prompt are cut down to the sections that share the most
+// words with the question.
+func (apa *AudioProcessActivities) AnswerQuestion(ctx context.Context, q ChatQuestion) (ChatAnswer, error) {
+	transcript, err := loadTranscript(ctx, apa.store, q.VideoID)
+
+	if err != nil {
+		return ChatAnswer{}, err
+	}
+
+	system := fmt.Sprintf(`You answer questions about the video %q using only its transcript below. %s Base every statement on the transcript and end it with the [hh:mm:ss] timestamp of the line it comes from. If the transcript does not cover the question, say so instead of guessing. Answer in the language of the question, in markdown, and keep the answer short unless asked for detail. Transcript: %s`,
+		q.Title, transcriptInstructions, relevantText(transcript, q.Question))
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.SystemMessage(system)}
+
+	for _, turn := range q.History {
+		messages = append(messages, openai.UserMessage(turn.Question), openai.AssistantMessage(turn.Answer))
+	}
+
+	messages = append(messages, openai.UserMessage(q.Question))
+
+	answer, err := apa.completeMessages(ctx, messages...)
+
+	if err != nil {
+		return ChatAnswer{}, err
+	}
+
+	end := -1.0
+
+	if n := len(transcript.Segments); n > 0 {
+		end = transcript.Segments[n-1].End
+	}
+
+	return ChatAnswer{Answer: answer, Citations: citations(answer, q.URL, end)}, nil
+}
+
+const transcriptInstructions = `Each line of the transcript starts with a [hh:mm:ss] timestamp of the moment it was said.`
+
+// relevantText is the timestamped transcript, or for long ones the sections
+// that best match the question, in their original order.
+func relevantText(transcript transcriber.Transcript, question string) string {
+	text := transcript.TimestampedText()
+
+	if transcriber.EstimateTokens(text) <= SinglePassTokenLimit {
+		return text
+	}
+
+	terms := make([]string, 0)
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		// Short words are mostly stop words that match every section.
+		if len([]rune(word)) >= 4 {
+			terms = append(terms, word)
+		}
+	}
+
+	sections := transcript.Sections(SectionTokenLimit)
+	texts := make([]string, len(sections))
+	scores := make([]int, len(sections))
+	order := make([]int, len(sections))
+
+	for i, section := range sections {
+		texts[i] = section.TimestampedText()
+		order[i] = i
+
+		lower := strings.ToLower(texts[i])
+
+		for _, term := range terms {
+			scores[i] += strings.Count(lower, term)
+		}
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int { return scores[b] - scores[a] })
+
+	picked := make([]int, 0)
+	tokens := 0
+
+	for _, i := range order {
+		t := transcriber.EstimateTokens(texts[i])
+
+		if tokens+t > SinglePassTokenLimit {
+			break
+		}
+
+		picked = append(picked, i)
+		tokens += t
+	}
+
+	slices.Sort(picked)
+
+	parts := make([]string, 0, len(picked))
+
+	for _, i := range picked {
+		parts = append(parts, strings.TrimSuffix(texts[i], "\n"))
+	}
+
+	return strings.Join(parts, "\n[...]\n")
+}
+
+// citations lists the distinct timestamps of an answer in the order they
+// first appear. Timestamps past end, the second at which the transcript
+// ends, were made up by the model and are dropped.
+func citations(answer string, videoURL string, end float64) []Citation {
+	cited := make([]Citation, 0)
+
+	for _, match := range timestampPattern.FindAllStringSubmatch(answer, -1) {
+		offset := parseTimestamp(match)
+
+		if float64(offset) > end {
+			continue
+		}
+
+		c := Citation{Timestamp: transcriber.FormatTimestamp(float64(offset)), Offset: offset}
+
+		if slices.ContainsFunc(cited, func(existing Citation) bool { return existing.Offset == c.Offset }) {
+			continue
+		}
+
+		if supportsDeepLinks(videoURL) {
+			c.URL = DeepLink(videoURL, c.Offset)
+		}
+
+		cited = append(cited, c)
+	}
+
+	return cited
+}
@@ -0,0 +1,62 @@
+package activity
+
+import (
+	"api/internal/summary/transcriber"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCitations(t *testing.T) {
+	answer := "It starts at [00:01:05] and ends at [01:10]. See [00:01:05] again. Later [05:12:00] and [00:02:01]."
+
+	got := citations(answer, "https://www.youtube.com/watch?v=abc", 120)
+
+	want := []Citation{
+		{Timestamp: "00:01:05", Offset: 65, URL: "https://www.youtube.com/watch?v=abc&t=65s"},
+		{Timestamp: "00:01:10", Offset: 70, URL: "https://www.youtube.com/watch?v=abc&t=70s"},
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("citations() = %v, want %v", got, want)
+	}
+
+	if got := citations(answer, "https://www.youtube.com/watch?v=abc", -1); len(got) != 0 {
+		t.Errorf("citations() without segments = %v, want none", got)
+	}
+
+	if got := citations("[00:00:10]", "https://example.com/video.mp4", 60); len(got) != 1 || got[0].URL != "" {
+		t.Errorf("citations() without deep links = %v, want one citation without a URL", got)
+	}
+}
+
+func TestRelevantText(t *testing.T) {
+	segments := make([]transcriber.Segment, 0)
+	filler := strings.Repeat("filler ", 150)
+
+	for i := range 400 {
+		text := filler
+
+		if i == 350 {
+			text += "penguins"
+		}
+
+		segments = append(segments, transcriber.Segment{Start: float64(i * 10), End: float64(i*10 + 10), Text: text})
+	}
+
+	got := relevantText(transcriber.NewTranscript(segments), "Where are the penguins?")
+
+	if !strings.Contains(got, "penguins") {
+		t.Error("relevantText() drops the section that matches the question")
+	}
+
+	if !strings.Contains(got, "\n[...]\n") {
+		t.Fatal("relevantText() does not mark skipped sections")
+	}
+
+	for _, line := range strings.Split(got, "\n") {
+		if strings.Contains(line, "[...]") && line != "[...]" {
+			t.Errorf("skip marker shares a line: %q", line)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package activity
+
+import (
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/api/googleapi"
+)
+
+// Application error types of activity failures. All but ErrTypeRateLimited
+// are non-retryable, as retrying cannot fix them, so the workflow fails right
+// away and says why.
+const (
+	// ErrTypeSourceUnavailable is a private, removed or missing video,
+	// episode, file or channel.
+	ErrTypeSourceUnavailable = "SourceUnavailable"
+	// ErrTypeInvalidCredentials is a missing or rejected API key.
+	ErrTypeInvalidCredentials = "InvalidCredentials"
+	// ErrTypeQuotaExceeded is an exhausted OpenAI or YouTube quota.
+	ErrTypeQuotaExceeded = "QuotaExceeded"
+	// ErrTypeContentPolicy is a model refusing the content.
+	ErrTypeContentPolicy = "ContentPolicy"
+	// ErrTypeInvalidRequest is a request an API rejected as malformed, for
+	// example for an unknown model.
+	ErrTypeInvalidRequest = "InvalidRequest"
+	// ErrTypeRateLimited is a rate limited API call, retried after the delay
+	// the API asked for.
+	ErrTypeRateLimited = "RateLimited"
+)
+
+// classify turns errors of the sources, OpenAI and the YouTube Data API into
+// application errors of the types above. Any other error is returned as is
+// and retried by the activity's retry policy.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *temporal.ApplicationError
+
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	var openAIErr *openai.Error
+
+	if errors.As(err, &openAIErr) {
+		return classifyOpenAI(err, openAIErr)
+	}
+
+	var googleErr *googleapi.Error
+
+	if errors.As(err, &googleErr) {
+		return classifyGoogle(err, googleErr)
+	}
+
+	switch {
+	case errors.Is(err, source.ErrUnavailable):
+		return nonRetryable(ErrTypeSourceUnavailable, err)
+	case errors.Is(err, shared.ErrMissingAPIKey):
+		return nonRetryable(ErrTypeInvalidCredentials, err)
+	}
+
+	return err
+}
+
+func classifyOpenAI(err error, apiErr *openai.Error) error {
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		return nonRetryable(ErrTypeInvalidCredentials, err)
+	case apiErr.Code == "insufficient_quota":
+		return nonRetryable(ErrTypeQuotaExceeded, err)
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return rateLimited(err, apiErr.Response)
+	case apiErr.Code == "content_policy_violation" || apiErr.Code == "content_filter":
+		return nonRetryable(ErrTypeContentPolicy, err)
+	case apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusNotFound:
+		return nonRetryable(ErrTypeInvalidRequest, err)
+	}
+
+	return err
+}
+
+func classifyGoogle(err error, apiErr *googleapi.Error) error {
+	reasons := make(map[string]bool, len(apiErr.Errors))
+
+	for _, item := range apiErr.Errors {
+		reasons[item.Reason] = true
+	}
+
+	switch {
+	case reasons["quotaExceeded"] || reasons["dailyLimitExceeded"]:
+		return nonRetryable(ErrTypeQuotaExceeded, err)
+	case reasons["rateLimitExceeded"] || reasons["userRateLimitExceeded"]:
+		return temporal.NewApplicationErrorWithCause(err.Error(), ErrTypeRateLimited, err)
+	case reasons["keyInvalid"] || reasons["keyExpired"] || reasons["accessNotConfigured"] ||
+		apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden:
+		return nonRetryable(ErrTypeInvalidCredentials, err)
+	case apiErr.Code == http.StatusNotFound:
+		return nonRetryable(ErrTypeSourceUnavailable, err)
+	case apiErr.Code == http.StatusBadRequest:
+		return nonRetryable(ErrTypeInvalidRequest, err)
+	}
+
+	return err
+}
+
+// contentPolicyError is returned when a model refuses to answer.
+func contentPolicyError(refusal string) error {
+	return temporal.NewNonRetryableApplicationError("the model refused: "+refusal, ErrTypeContentPolicy, nil)
+}
+
+func nonRetryable(errType string, err error) error {
+	return temporal.NewNonRetryableApplicationError(err.Error(), errType, err)
+}
+
+// rateLimited retries after the Retry-After delay of resp, when it has one.
+func rateLimited(err error, resp *http.Response) error {
+	options := temporal.ApplicationErrorOptions{Cause: err}
+
+	if resp != nil {
+		if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+			options.NextRetryDelay = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return temporal.NewApplicationErrorWithOptions(err.Error(), ErrTypeRateLimited, options)
+}
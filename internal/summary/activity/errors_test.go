@@ -0,0 +1,120 @@
+package activity
+
+import (
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/api/googleapi"
+)
+
+func openAIError(status int, code string, header http.Header) error {
+	resp := &http.Response{StatusCode: status, Header: header}
+
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+
+	return fmt.Errorf("request failed: %w", &openai.Error{
+		Code:       code,
+		StatusCode: status,
+		Request:    httptest.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil),
+		Response:   resp,
+	})
+}
+
+func googleError(code int, reasons ...string) error {
+	items := make([]googleapi.ErrorItem, len(reasons))
+
+	for i, reason := range reasons {
+		items[i] = googleapi.ErrorItem{Reason: reason}
+	}
+
+	return fmt.Errorf("search failed: %w", &googleapi.Error{Code: code, Errors: items})
+}
+
+func TestClassify(t *testing.T) {
+	plain := errors.New("connection reset")
+	rateLimitedAfter := http.Header{"Retry-After": []string{"7"}}
+
+	tests := []struct {
+		name string
+		err  error
+		// errType is empty when err is expected back as is.
+		errType      string
+		nonRetryable bool
+		delay        time.Duration
+	}{
+		{name: "nil", err: nil},
+		{name: "other errors", err: plain},
+		{name: "source unavailable", err: fmt.Errorf("yt-dlp: %w", source.ErrUnavailable), errType: ErrTypeSourceUnavailable, nonRetryable: true},
+		{name: "missing api key", err: fmt.Errorf("youtube: %w", shared.ErrMissingAPIKey), errType: ErrTypeInvalidCredentials, nonRetryable: true},
+
+		{name: "openai unauthorized", err: openAIError(http.StatusUnauthorized, "invalid_api_key", nil), errType: ErrTypeInvalidCredentials, nonRetryable: true},
+		{name: "openai forbidden", err: openAIError(http.StatusForbidden, "", nil), errType: ErrTypeInvalidCredentials, nonRetryable: true},
+		{name: "openai quota", err: openAIError(http.StatusTooManyRequests, "insufficient_quota", nil), errType: ErrTypeQuotaExceeded, nonRetryable: true},
+		{name: "openai rate limit", err: openAIError(http.StatusTooManyRequests, "rate_limit_exceeded", nil), errType: ErrTypeRateLimited},
+		{name: "openai rate limit with retry-after", err: openAIError(http.StatusTooManyRequests, "", rateLimitedAfter), errType: ErrTypeRateLimited, delay: 7 * time.Second},
+		{name: "openai invalid retry-after", err: openAIError(http.StatusTooManyRequests, "", http.Header{"Retry-After": []string{"soon"}}), errType: ErrTypeRateLimited},
+		{name: "openai content policy", err: openAIError(http.StatusBadRequest, "content_policy_violation", nil), errType: ErrTypeContentPolicy, nonRetryable: true},
+		{name: "openai content filter", err: openAIError(http.StatusBadRequest, "content_filter", nil), errType: ErrTypeContentPolicy, nonRetryable: true},
+		{name: "openai bad request", err: openAIError(http.StatusBadRequest, "", nil), errType: ErrTypeInvalidRequest, nonRetryable: true},
+		{name: "openai unknown model", err: openAIError(http.StatusNotFound, "model_not_found", nil), errType: ErrTypeInvalidRequest, nonRetryable: true},
+		{name: "openai server error", err: openAIError(http.StatusInternalServerError, "", nil)},
+
+		{name: "google quota", err: googleError(http.StatusForbidden, "quotaExceeded"), errType: ErrTypeQuotaExceeded, nonRetryable: true},
+		{name: "google daily limit", err: googleError(http.StatusForbidden, "dailyLimitExceeded"), errType: ErrTypeQuotaExceeded, nonRetryable: true},
+		{name: "google rate limit", err: googleError(http.StatusForbidden, "rateLimitExceeded"), errType: ErrTypeRateLimited},
+		{name: "google user rate limit", err: googleError(http.StatusForbidden, "userRateLimitExceeded"), errType: ErrTypeRateLimited},
+		{name: "google invalid key", err: googleError(http.StatusBadRequest, "keyInvalid"), errType: ErrTypeInvalidCredentials, nonRetryable: true},
+		{name: "google forbidden", err: googleError(http.StatusForbidden), errType: ErrTypeInvalidCredentials, nonRetryable: true},
+		{name: "google not found", err: googleError(http.StatusNotFound, "channelNotFound"), errType: ErrTypeSourceUnavailable, nonRetryable: true},
+		{name: "google bad request", err: googleError(http.StatusBadRequest, "invalid"), errType: ErrTypeInvalidRequest, nonRetryable: true},
+		{name: "google server error", err: googleError(http.StatusServiceUnavailable, "backendError")},
+
+		{name: "already classified", err: contentPolicyError("no"), errType: ErrTypeContentPolicy, nonRetryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classify(tt.err)
+
+			var appErr *temporal.ApplicationError
+
+			if tt.errType == "" {
+				if got != tt.err {
+					t.Fatalf("classify() = %v, want the error as is", got)
+				}
+
+				return
+			}
+
+			if !errors.As(got, &appErr) {
+				t.Fatalf("classify() = %v, want an application error", got)
+			}
+
+			if appErr.Type() != tt.errType {
+				t.Errorf("type = %q, want %q", appErr.Type(), tt.errType)
+			}
+
+			if appErr.NonRetryable() != tt.nonRetryable {
+				t.Errorf("non-retryable = %v, want %v", appErr.NonRetryable(), tt.nonRetryable)
+			}
+
+			if appErr.NextRetryDelay() != tt.delay {
+				t.Errorf("next retry delay = %v, want %v", appErr.NextRetryDelay(), tt.delay)
+			}
+
+			if !errors.Is(got, tt.err) {
+				t.Errorf("classify() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package activity
+
+import (
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type PlaylistInfo struct {
+	Title  string
+	Videos []shared.SearchResult
+}
+
+type ytDlpPlaylist struct {
+	Title   string `json:"title"`
+	Entries []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"entries"`
+}
+
+// ListPlaylistVideos lists the videos of a playlist or channel without
+// downloading them. maxVideos limits how many are returned, 0 means all.
+func ListPlaylistVideos(ctx context.Context, URL string, maxVideos int) (*PlaylistInfo, error) {
+	args := []string{
+		"--flat-playlist",
+		"--dump-single-json",
+		"--ignore-errors",
+	}
+
+	if maxVideos > 0 {
+		args = append(args, "--playlist-end", strconv.Itoa(maxVideos))
+	}
+
+	// "--" ends the options, so a URL starting with "-" is not read as one.
+	args = append(args, "--", channelVideosURL(URL))
+
+	out, err := exec.CommandContext(ctx, "yt-dlp", args...).Output()
+
+	if err != nil {
+		return nil, classify(source.YtDlpError(fmt.Sprintf("yt-dlp failed to list %s", URL), err, ""))
+	}
+
+	var playlist ytDlpPlaylist
+
+	if err := json.Unmarshal(out, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to decode playlist: %w", err)
+	}
+
+	info := &PlaylistInfo{
+		Title:  playlist.Title,
+		Videos: make([]shared.SearchResult, 0, len(playlist.Entries)),
+	}
+
+	for _, entry := range playlist.Entries {
+		var r shared.SearchResult
+		r.Title = entry.Title
+		r.URL = entry.URL
+
+		if r.URL == "" {
+			r.URL = fmt.Sprintf("https://www.youtube.com/watch?v=%s", entry.ID)
+		}
+
+		info.Videos = append(info.Videos, r)
+	}
+
+	return info, nil
+}
+
+// channelVideosURL points bare YouTube channel URLs at their "Videos" tab, as
+// yt-dlp otherwise lists the channel's tabs instead of its uploads.
+func channelVideosURL(URL string) string {
+	u, err := url.Parse(URL)
+
+	if err != nil || !strings.HasSuffix(u.Hostname(), "youtube.com") {
+		return URL
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch {
+	case len(parts) == 1 && strings.HasPrefix(parts[0], "@"):
+	case len(parts) == 2 && (parts[0] == "channel" || parts[0] == "c" || parts[0] == "user"):
+	default:
+		return URL
+	}
+
+	u.Path = "/" + strings.Join(append(parts, "videos"), "/")
+
+	return u.String()
+}
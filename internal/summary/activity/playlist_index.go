@@ -0,0 +1,73 @@
+package activity
+
+import (
+	"api/internal/summary/render"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+type PlaylistIndexEntry struct {
+	Title      string
+	URL        string
+	SummaryKey string
+	Error      string
+}
+
+type PlaylistIndex struct {
+	Title   string
+	URL     string
+	Entries []PlaylistIndexEntry
+}
+
+// WritePlaylistIndex stores a document linking every summary of a playlist
+// next to the summaries themselves and returns its storage key.
+func (aa *ArtifactActivities) WritePlaylistIndex(
+	ctx context.Context,
+	fileName string,
+	index PlaylistIndex,
+	format render.Format,
+) (string, error) {
+	renderer, err := render.New(format)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	// The markdown renderer writes the document as is, the others add the
+	// title themselves.
+	if _, ok := renderer.(render.MarkdownRenderer); ok {
+		fmt.Fprintf(&b, "# %s\n\n", index.Title)
+	}
+
+	for i, entry := range index.Entries {
+		switch {
+		case entry.Error != "":
+			fmt.Fprintf(&b, "%d. %s ([video](%s)) - failed: %s\n", i+1, entry.Title, entry.URL, entry.Error)
+		default:
+			fmt.Fprintf(&b, "%d. [%s](%s) ([video](%s))\n", i+1, entry.Title, path.Base(entry.SummaryKey), entry.URL)
+		}
+	}
+
+	content, err := renderer.Render(ctx, render.Document{
+		Title:    index.Title,
+		URL:      index.URL,
+		Markdown: b.String(),
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	outputKey := fmt.Sprintf("%s%s.%s", summariesPrefix, fileName, renderer.Extension())
+
+	if err := aa.store.Put(ctx, outputKey, bytes.NewReader(content)); err != nil {
+		return "", err
+	}
+
+	return outputKey, nil
+}
@@ -1,86 +1,231 @@
 package activity
 
 import (
+	"api/internal/summary/language"
+	"api/internal/summary/render"
+	"api/internal/summary/storage"
+	"api/internal/summary/style"
+	"api/internal/summary/transcriber"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/openai/openai-go"
 )
 
 type AudioProcessActivities struct {
 	opanAPIClient openai.Client
+	transcriber   transcriber.Transcriber
+	store         storage.Storage
+	// summaryModel is the chat model that writes summaries.
+	summaryModel string
+	// styles holds the prompt templates summaries are written with.
+	styles *style.Library
 }
 
-func NewAudioProcessActivities(opanAPIClient openai.Client) *AudioProcessActivities {
+func NewAudioProcessActivities(
+	opanAPIClient openai.Client,
+	audioTranscriber transcriber.Transcriber,
+	store storage.Storage,
+	summaryModel string,
+	styles *style.Library,
+) *AudioProcessActivities {
 	return &AudioProcessActivities{
 		opanAPIClient,
+		audioTranscriber,
+		store,
+		summaryModel,
+		styles,
 	}
 }
 
-func (apa *AudioProcessActivities) TranscribeAudio(ctx context.Context, filePath string) (string, error) {
-	file, err := os.Open(filePath)
+// transcriptionSpeed is about how many seconds of audio are transcribed per
+// second, to estimate the progress of a chunk.
+const transcriptionSpeed = 10
+
+// TranscribeAudio transcribes an audio chunk, heartbeating a Progress
+// estimated from its length.
+func (apa *AudioProcessActivities) TranscribeAudio(
+	ctx context.Context,
+	audioKey string,
+) (transcriber.Transcript, error) {
+	filePath, cleanup, err := downloadToTemp(ctx, apa.store, audioKey)
 
 	if err != nil {
-		return "", err
+		return transcriber.Transcript{}, err
 	}
 
-	transcription, err := apa.opanAPIClient.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
-		Model: openai.AudioModelWhisper1,
-		File:  file,
-	})
+	defer cleanup()
 
-	if err != nil {
-		return "", err
+	report, stop := heartbeatProgress(ctx, Progress{Detail: "transcribing"})
+	defer stop()
+
+	// Transcribers don't report how far they got, so the progress is
+	// estimated from the length of the chunk.
+	if duration, err := probeDuration(ctx, filePath); err == nil && duration > 0 {
+		expected := time.Duration(duration / transcriptionSpeed * float64(time.Second))
+		stopEstimate := estimateProgress(ctx, report, "transcribing", expected)
+		defer stopEstimate()
 	}
 
-	return transcription.Text, nil
+	transcript, err := apa.transcriber.Transcribe(ctx, filePath)
+
+	return transcript, classify(err)
 }
 
-const summaryFormat = "md"
+// summariesPrefix is where rendered summaries are stored.
+const summariesPrefix = "summaries/"
 
-func CreateSummaryOutputFile(ctx context.Context, fileName string) (string, error) {
-	dir, _ := os.Getwd()
-	outputPath := fmt.Sprintf("%s/output/%s.%s", dir, fileName, summaryFormat)
+// SummaryOutputKey returns the key the summary of fileName is written to in
+// format. Nothing is stored there until OutputSummaryToFile writes the whole
+// summary, so readers never see a partial one. It only depends on its
+// arguments, so workflows call it directly.
+func SummaryOutputKey(fileName string, format render.Format) (string, error) {
+	if format == "" {
+		format = render.FormatMarkdown
+	}
 
-	transcriptionFile, err := os.Create(outputPath)
+	if !slices.Contains(render.Formats, format) {
+		return "", fmt.Errorf("unsupported summary format %q", format)
+	}
 
-	if err != nil {
-		return "", err
+	return fmt.Sprintf("%s%s.%s", summariesPrefix, fileName, format), nil
+}
+
+// LanguageOutputKey is where the summary written to outputKey is stored in
+// another language, such as "summaries/<id>.de.md" for "summaries/<id>.md".
+func LanguageOutputKey(outputKey string, language string) string {
+	ext := path.Ext(outputKey)
+
+	return strings.TrimSuffix(outputKey, ext) + "." + language + ext
+}
+
+// MissingOutputFiles returns the keys that hold no output yet, which are the
+// ones a run creates when it writes them.
+func (aa *ArtifactActivities) MissingOutputFiles(ctx context.Context, keys []string) ([]string, error) {
+	missing := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		exists, err := aa.store.Exists(ctx, key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing, nil
+}
+
+// RemoveOutputFiles deletes the outputs stored under keys.
+func (aa *ArtifactActivities) RemoveOutputFiles(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if err := aa.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ResolveStyle pins a style spec, "name" or "name@version", to the version
+// every later activity of the run uses. An empty spec is the default style.
+func (apa *AudioProcessActivities) ResolveStyle(ctx context.Context, spec string) (style.Ref, error) {
+	ref, err := apa.styles.Resolve(spec)
+
+	if errors.Is(err, style.ErrUnknownStyle) {
+		return ref, nonRetryable(ErrTypeInvalidRequest, err)
 	}
 
-	defer transcriptionFile.Close()
+	return ref, err
+}
+
+// styleFor returns the style ref points at, the default style for a zero
+// ref.
+func (apa *AudioProcessActivities) styleFor(ref style.Ref) (*style.Style, error) {
+	if ref == (style.Ref{}) {
+		var err error
+
+		if ref, err = apa.styles.Resolve(""); err != nil {
+			return nil, err
+		}
+	}
+
+	s, err := apa.styles.Get(ref)
+
+	if errors.Is(err, style.ErrUnknownStyle) {
+		return nil, nonRetryable(ErrTypeInvalidRequest, err)
+	}
 
-	return outputPath, nil
+	return s, err
 }
 
+// promptData is what style templates are rendered with for a text in the
+// language with the given ISO 639-1 code, empty when it is unknown.
+func promptData(text string, code string) style.Data {
+	data := style.Data{
+		Text:                  text,
+		TimestampInstructions: timestampInstructions,
+		LanguageInstructions:  languageInstructions(code),
+	}
+
+	if code != "" {
+		data.Language = language.Name(code)
+	}
+
+	return data
+}
+
+// SummarizeTranscription summarizes a transcript in its spoken language,
+// given by ISO 639-1 code, empty when it is unknown.
 func (apa *AudioProcessActivities) SummarizeTranscription(
 	ctx context.Context,
 	transcription string,
+	language string,
+	ref style.Ref,
 ) (string, error) {
+	s, err := apa.styleFor(ref)
 
-	prompt := fmt.Sprintf(`Could you provide a concise and comprehensive summary of the given text in markdown format? Send only summary content without any other comments from you like confirmation message or any questions after you finish with content, also don't wrap your answer in "'''markdown'''". The summary should capture the main points and key details of the text while conveying the author's intended meaning accurately. Please ensure that the summary is well-organized and easy to read, with clear headings and subheadings to guide the reader through each section. The length of the summary should be appropriate to capture the main points and key details of the text, without including unnecessary information or becoming overly long. Text: %s`,
-		transcription)
-
-	params := openai.ChatCompletionNewParams{
-		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(prompt),
-		},
-		Model: openai.ChatModelGPT4o,
-		Seed:  openai.Int(0),
+	if err != nil {
+		return "", err
 	}
 
-	completion, err := apa.opanAPIClient.Chat.Completions.New(ctx, params)
+	prompt, err := s.Summary(promptData(transcription, language))
 
 	if err != nil {
-		return "", err
+		return "", nonRetryable(ErrTypeInvalidRequest, err)
 	}
 
-	return completion.Choices[0].Message.Content, nil
+	return apa.complete(ctx, prompt)
 }
 
-func OutputSummaryToFile(ctx context.Context, summary string, outputFilePath string) (bool, error) {
-	err := os.WriteFile(outputFilePath, []byte(summary), 0644)
+func (aa *ArtifactActivities) OutputSummaryToFile(
+	ctx context.Context,
+	doc render.Document,
+	format render.Format,
+	outputKey string,
+) (bool, error) {
+	renderer, err := render.New(format)
+
+	if err != nil {
+		return false, err
+	}
+
+	content, err := renderer.Render(ctx, doc)
+
+	if err != nil {
+		return false, err
+	}
+
+	err = aa.store.Put(ctx, outputKey, bytes.NewReader(content))
 
 	if err != nil {
 		return false, err
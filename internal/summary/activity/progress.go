@@ -0,0 +1,111 @@
+package activity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// Progress is the heartbeat detail of activities that report how far they
+// got, so clients can read it from the workflow's pending activities.
+type Progress struct {
+	Percent float64
+	Detail  string
+}
+
+// DownloadProgress is the heartbeat detail of RetrieveAudio. A retry on the
+// same host continues the partial download left in WorkDir.
+type DownloadProgress struct {
+	Progress
+	Hostname string
+	WorkDir  string
+}
+
+// progressHeartbeatInterval is how often the last reported progress is
+// heartbeated again while an activity reports nothing new.
+const progressHeartbeatInterval = 10 * time.Second
+
+// estimatedProgressCap is the highest percentage an estimated progress
+// reaches, as the step may take longer than expected.
+const estimatedProgressCap = 95
+
+// heartbeatProgress heartbeats every reported progress, and the last one
+// again every progressHeartbeatInterval until stop is called. Activities
+// keep heartbeating during steps that cannot report progress, such as a
+// single API call.
+func heartbeatProgress[T any](ctx context.Context, initial T) (report func(T), stop func()) {
+	var mu sync.Mutex
+	last := initial
+	done := make(chan struct{})
+
+	activity.RecordHeartbeat(ctx, initial)
+
+	go func() {
+		ticker := time.NewTicker(progressHeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				mu.Lock()
+				p := last
+				mu.Unlock()
+
+				activity.RecordHeartbeat(ctx, p)
+			}
+		}
+	}()
+
+	report = func(p T) {
+		mu.Lock()
+		last = p
+		mu.Unlock()
+
+		activity.RecordHeartbeat(ctx, p)
+	}
+
+	var once sync.Once
+
+	stop = func() {
+		once.Do(func() { close(done) })
+	}
+
+	return report, stop
+}
+
+// estimateProgress reports the share of expected that has elapsed as the
+// progress of a step that cannot tell how far it got, every
+// progressHeartbeatInterval until stop is called.
+func estimateProgress(ctx context.Context, report func(Progress), detail string, expected time.Duration) (stop func()) {
+	start := time.Now()
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(progressHeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				elapsed := float64(time.Since(start)) / float64(expected) * 100
+				report(Progress{Percent: min(elapsed, estimatedProgressCap), Detail: detail})
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -8,16 +8,35 @@ import (
 	"github.com/nlpodyssey/openai-agents-go/agents"
 )
 
-func Refine(ctx context.Context, query string) (*shared.WithRefineOutput, error) {
-	triageAgent := shared.NewTriageAgent()
+// SearchActivities run the refine and search agents, whose search tool
+// delegates to provider.
+type SearchActivities struct {
+	provider shared.SearchProvider
+	// model is the chat model the agents run on.
+	model string
+}
+
+func NewSearchActivities(provider shared.SearchProvider, model string) *SearchActivities {
+	return &SearchActivities{
+		provider,
+		model,
+	}
+}
+
+func (sa *SearchActivities) Refine(ctx context.Context, query string) (*shared.WithRefineOutput, error) {
+	triageAgent := shared.NewTriageAgent(sa.provider, sa.model)
 	result, err := agents.Run(ctx, triageAgent, query)
 
 	if err != nil {
 		fmt.Printf("Issue running triage agent: %s\n", err.Error())
-		return nil, err
+		return nil, classify(err)
 	}
 
-	output := result.FinalOutput.(shared.WithRefineOutput)
+	output, ok := result.FinalOutput.(shared.WithRefineOutput)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected triage agent output %T", result.FinalOutput)
+	}
 
 	fmt.Printf("Triage agent output: %v", output)
 
@@ -27,19 +46,31 @@ func Refine(ctx context.Context, query string) (*shared.WithRefineOutput, error)
 	}, nil
 }
 
-func Search(ctx context.Context, query string) (*shared.WithRefineOutput, error) {
-	searchAgent := shared.NewSearchAgent()
+func (sa *SearchActivities) Search(ctx context.Context, query string) (*shared.WithRefineOutput, error) {
+	searchAgent := shared.NewSearchAgent(sa.provider, sa.model)
 	result, err := agents.Run(ctx, searchAgent, query)
 
 	if err != nil {
 		fmt.Printf("Issue while running search agent: %v", err.Error())
-		return nil, err
+		return nil, classify(err)
 	}
 
-	output := result.FinalOutput.(shared.WithRefineOutput)
+	output, ok := result.FinalOutput.(shared.WithRefineOutput)
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected search agent output %T", result.FinalOutput)
+	}
 
 	return &shared.WithRefineOutput{
 		RefineQuestions: output.RefineQuestions,
 		SearchResults:   output.SearchResults,
 	}, nil
 }
+
+// SearchProviders queries the search providers directly, without the agents,
+// for callers that already know the exact search parameters.
+func (sa *SearchActivities) SearchProviders(ctx context.Context, params shared.SearchParams) ([]shared.SearchResult, error) {
+	results, err := sa.provider.Search(ctx, params)
+
+	return results, classify(err)
+}
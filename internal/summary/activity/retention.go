@@ -0,0 +1,156 @@
+package activity
+
+import (
+	"api/internal/summary/cache"
+	"context"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// ArtifactKind groups the stored objects that share a retention period.
+type ArtifactKind string
+
+const (
+	// KindAudio is downloaded audio and its chunks.
+	KindAudio ArtifactKind = "audio"
+	// KindTranscript is cached transcripts.
+	KindTranscript ArtifactKind = "transcript"
+	// KindSummary is cached summaries of every style, their translations
+	// and metadata.
+	KindSummary ArtifactKind = "summary"
+	// KindOutput is rendered summaries and playlist indexes.
+	KindOutput ArtifactKind = "output"
+)
+
+// ArtifactKinds lists every kind in the order cleanup reports them.
+var ArtifactKinds = []ArtifactKind{KindAudio, KindTranscript, KindSummary, KindOutput}
+
+// RetentionPolicy is how long each kind of artifact is kept after it was
+// last written. Zero keeps it forever.
+type RetentionPolicy struct {
+	Audio      time.Duration
+	Transcript time.Duration
+	Summary    time.Duration
+	Output     time.Duration
+}
+
+func (p RetentionPolicy) maxAge(kind ArtifactKind) time.Duration {
+	switch kind {
+	case KindAudio:
+		return p.Audio
+	case KindTranscript:
+		return p.Transcript
+	case KindSummary:
+		return p.Summary
+	case KindOutput:
+		return p.Output
+	default:
+		return 0
+	}
+}
+
+type ArtifactCleanup struct {
+	Kind    ArtifactKind
+	Objects int
+	Bytes   int64
+}
+
+// CleanupReport counts the objects deleted by RemoveExpiredArtifacts and the
+// space they took, in total and per kind.
+type CleanupReport struct {
+	Kinds          []ArtifactCleanup
+	Objects        int
+	ReclaimedBytes int64
+}
+
+func (r *CleanupReport) add(kind ArtifactKind, size int64) {
+	r.Objects++
+	r.ReclaimedBytes += size
+
+	for i := range r.Kinds {
+		if r.Kinds[i].Kind == kind {
+			r.Kinds[i].Objects++
+			r.Kinds[i].Bytes += size
+			return
+		}
+	}
+
+	r.Kinds = append(r.Kinds, ArtifactCleanup{Kind: kind, Objects: 1, Bytes: size})
+}
+
+// RemoveExpiredArtifacts deletes the cached and rendered artifacts that are
+// older than the policy allows. It heartbeats the report so far, which a
+// retry continues from, as objects deleted by an earlier attempt are no
+// longer listed.
+func (aa *ArtifactActivities) RemoveExpiredArtifacts(ctx context.Context, policy RetentionPolicy) (CleanupReport, error) {
+	var report CleanupReport
+
+	if activity.HasHeartbeatDetails(ctx) {
+		activity.GetHeartbeatDetails(ctx, &report)
+	}
+
+	now := time.Now()
+
+	for _, prefix := range []string{"cache/", summariesPrefix} {
+		objects, err := aa.store.List(ctx, prefix)
+
+		if err != nil {
+			return report, err
+		}
+
+		for _, object := range objects {
+			activity.RecordHeartbeat(ctx, report)
+
+			kind := artifactKind(object.Key)
+			maxAge := policy.maxAge(kind)
+
+			if maxAge <= 0 || now.Sub(object.LastModified) < maxAge {
+				continue
+			}
+
+			if err := aa.store.Delete(ctx, object.Key); err != nil {
+				return report, err
+			}
+
+			report.add(kind, object.Size)
+		}
+	}
+
+	return report, nil
+}
+
+// artifactKind tells the kind of an object from its key. Keys it does not
+// recognize are reported as an empty kind and never deleted.
+func artifactKind(key string) ArtifactKind {
+	if strings.HasPrefix(key, summariesPrefix) {
+		return KindOutput
+	}
+
+	// Cache keys are "cache/<video id>/<artifact>", chunks two levels deeper.
+	videoID, artifact, ok := strings.Cut(strings.TrimPrefix(key, "cache/"), "/")
+
+	if !ok {
+		return ""
+	}
+
+	switch cache.Artifact(artifact) {
+	case cache.ArtifactAudio:
+		return KindAudio
+	case cache.ArtifactTranscript:
+		return KindTranscript
+	case cache.ArtifactMetadata:
+		return KindSummary
+	}
+
+	if cache.IsSummary(cache.Artifact(artifact)) {
+		return KindSummary
+	}
+
+	if strings.HasPrefix(key, audioChunkPrefix(cache.Key(videoID, cache.ArtifactAudio), "")) {
+		return KindAudio
+	}
+
+	return ""
+}
@@ -0,0 +1,230 @@
+package activity
+
+import (
+	"api/internal/summary/storage"
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/testsuite"
+)
+
+// memStorage is an in-memory storage.Storage whose objects have a fixed
+// modification time.
+type memStorage struct {
+	mu      sync.Mutex
+	objects map[string]storage.Object
+	content map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: make(map[string]storage.Object), content: make(map[string][]byte)}
+}
+
+func (s *memStorage) putAt(key string, content string, modified time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = storage.Object{Key: key, Size: int64(len(content)), LastModified: modified}
+	s.content[key] = []byte(content)
+}
+
+func (s *memStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return err
+	}
+
+	s.putAt(key, string(content), time.Now())
+
+	return nil
+}
+
+func (s *memStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, ok := s.content[key]
+
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *memStorage) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.objects[key]
+
+	return ok, nil
+}
+
+func (s *memStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	delete(s.content, key)
+
+	return nil
+}
+
+func (s *memStorage) List(ctx context.Context, prefix string) ([]storage.Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects := make([]storage.Object, 0)
+
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, object)
+		}
+	}
+
+	slices.SortFunc(objects, func(a, b storage.Object) int { return strings.Compare(a.Key, b.Key) })
+
+	return objects, nil
+}
+
+func (s *memStorage) keys() []string {
+	objects, _ := s.List(context.Background(), "")
+	keys := make([]string, len(objects))
+
+	for i, object := range objects {
+		keys[i] = object.Key
+	}
+
+	return keys
+}
+
+func TestArtifactKind(t *testing.T) {
+	tests := []struct {
+		key  string
+		want ArtifactKind
+	}{
+		{"cache/youtube-abc/audio.mp3", KindAudio},
+		{"cache/youtube-abc/audio_chunks/run-1/chunk_000.mp3", KindAudio},
+		{"cache/youtube-abc/audio_chunks/chunk_000.mp3", KindAudio},
+		{"cache/youtube-abc/transcript.json", KindTranscript},
+		{"cache/youtube-abc/summary.md", KindSummary},
+		{"cache/youtube-abc/summary.tldr-v1.md", KindSummary},
+		{"cache/youtube-abc/summary.de.md", KindSummary},
+		{"cache/youtube-abc/metadata.json", KindSummary},
+		{"summaries/youtube-abc.md", KindOutput},
+		{"summaries/youtube-abc.de.pdf", KindOutput},
+		{"summaries/playlist-xyz.html", KindOutput},
+		// Keys it does not recognize are never deleted.
+		{"cache/youtube-abc", ""},
+		{"cache/youtube-abc/notes.txt", ""},
+		{"cache/youtube-abc/summary.txt", ""},
+		{"cache/youtube-abc/nested/summary.md", ""},
+		{"cache/youtube-abc/other_chunks/chunk_000.mp3", ""},
+		{"subscriptions/abc/processed.json", ""},
+		{"cachefile", ""},
+	}
+
+	for _, tt := range tests {
+		if got := artifactKind(tt.key); got != tt.want {
+			t.Errorf("artifactKind(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveExpiredArtifacts(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		// removed are the keys expected to be deleted, the others are kept.
+		removed []string
+	}{
+		{
+			name:   "zero keeps everything",
+			policy: RetentionPolicy{},
+		},
+		{
+			name:   "audio and its chunks",
+			policy: RetentionPolicy{Audio: 7 * day},
+			removed: []string{
+				"cache/old/audio.mp3",
+				"cache/old/audio_chunks/run/chunk_000.mp3",
+			},
+		},
+		{
+			name:   "each kind by its own age",
+			policy: RetentionPolicy{Audio: 30 * day, Transcript: 7 * day, Summary: 7 * day, Output: 1 * day},
+			removed: []string{
+				"cache/old/metadata.json",
+				"cache/old/summary.md",
+				"cache/old/transcript.json",
+				"summaries/new.md",
+				"summaries/old.md",
+			},
+		},
+		{
+			name:   "objects exactly as old as the limit are removed",
+			policy: RetentionPolicy{Output: 2 * day},
+			removed: []string{
+				"summaries/new.md",
+				"summaries/old.md",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStorage()
+			store.putAt("cache/old/audio.mp3", "audio", now.Add(-10*day))
+			store.putAt("cache/old/audio_chunks/run/chunk_000.mp3", "chunk", now.Add(-10*day))
+			store.putAt("cache/old/transcript.json", "{}", now.Add(-10*day))
+			store.putAt("cache/old/summary.md", "summary", now.Add(-10*day))
+			store.putAt("cache/old/metadata.json", "{}", now.Add(-10*day))
+			store.putAt("cache/old/notes.txt", "unknown", now.Add(-1000*day))
+			store.putAt("cache/new/audio.mp3", "audio", now.Add(-time.Hour))
+			store.putAt("cache/new/transcript.json", "{}", now.Add(-time.Hour))
+			store.putAt("summaries/old.md", "old", now.Add(-10*day))
+			store.putAt("summaries/new.md", "new", now.Add(-2*day))
+			store.putAt("subscriptions/abc/processed.json", "[]", now.Add(-1000*day))
+
+			before := store.keys()
+
+			var s testsuite.WorkflowTestSuite
+			env := s.NewTestActivityEnvironment()
+			aa := NewArtifactActivities(store)
+			env.RegisterActivity(aa)
+
+			result, err := env.ExecuteActivity(aa.RemoveExpiredArtifacts, tt.policy)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var report CleanupReport
+
+			if err := result.Get(&report); err != nil {
+				t.Fatal(err)
+			}
+
+			want := slices.DeleteFunc(before, func(key string) bool { return slices.Contains(tt.removed, key) })
+
+			if got := store.keys(); !slices.Equal(got, want) {
+				t.Errorf("kept %v, want %v", got, want)
+			}
+
+			if report.Objects != len(tt.removed) {
+				t.Errorf("report.Objects = %d, want %d", report.Objects, len(tt.removed))
+			}
+		})
+	}
+}
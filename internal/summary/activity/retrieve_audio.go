@@ -1,42 +1,161 @@
 package activity
 
 import (
-	"fmt"
+	"api/internal/summary/cache"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"context"
+	"errors"
 	"os"
-	"os/exec"
 
-	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 )
 
-const format = "mp3"
+// DownloadAttempts is how many times RetrieveAudio is attempted. The last
+// attempt removes its partial download, as no retry resumes it.
+const DownloadAttempts = 5
 
 type RetrieveAudioResult struct {
-	OutputPath string
-	FileName   string
+	OutputKey string
+	FileName  string
 }
 
-func RetrieveAudio(URL string) (*RetrieveAudioResult, error) {
-	dir, _ := os.Getwd()
+// ResolveSource pins a source reference to a concrete item, for example the
+// latest episode of a podcast, so retries and the cache keep pointing at the
+// same audio.
+func ResolveSource(ctx context.Context, ref source.Ref) (*source.Item, error) {
+	src, err := source.New(ref)
 
-	outputDir := fmt.Sprintf("%s/output", dir)
-	fileName := uuid.New().String()
-	outputPath := fmt.Sprintf("%s/%s.%s", outputDir, fileName, format)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, title, err := src.Resolve(ctx)
 
-	cmd := exec.Command("yt-dlp",
-		"-f", "bestaudio",
-		"--extract-audio",
-		"--audio-format", format,
-		"--audio-quality", "0", // best quality
-		"-o", outputPath,
-		URL,
-	)
+	if err != nil {
+		return nil, classify(err)
+	}
 
-	if err := cmd.Run(); err != nil {
+	return &source.Item{
+		Ref:   resolved,
+		Title: title,
+	}, nil
+}
+
+// RetrieveAudio fetches the audio of a source and stores it in the cache.
+// The object is only written once the download has finished, so an
+// interrupted run never leaves a cached partial file. The download progress
+// is heartbeated as a DownloadProgress, and a retry on the same host resumes
+// the partial download of the failed attempt.
+func (aa *ArtifactActivities) RetrieveAudio(
+	ctx context.Context,
+	ref source.Ref,
+	videoID string,
+) (result *RetrieveAudioResult, err error) {
+	src, err := source.New(ref)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	workDir := resumableWorkDir(ctx, hostname)
+
+	if workDir != "" {
+		activity.GetLogger(ctx).Info("Resuming partial download", "WorkDir", workDir)
+	} else {
+		workDir, err = os.MkdirTemp("", "retrieve-audio-*")
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The partial download is kept for the next attempt unless this one
+	// succeeded, the workflow cancelled it or no attempt follows. A worker
+	// shutdown also cancels ctx, but then the download is kept for the retry.
+	defer func() {
+		if err == nil || canceledByWorkflow(ctx) || lastAttempt(ctx, err) {
+			os.RemoveAll(workDir)
+		}
+	}()
+
+	progress := DownloadProgress{
+		Progress: Progress{Detail: "downloading"},
+		Hostname: hostname,
+		WorkDir:  workDir,
+	}
+
+	report, stop := heartbeatProgress(ctx, progress)
+	defer stop()
+
+	fetchCtx := source.WithProgress(ctx, func(fraction float64) {
+		progress.Percent = fraction * 100
+		report(progress)
+	})
+
+	downloadPath, err := src.Fetch(fetchCtx, workDir)
+
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	progress.Percent = 100
+	progress.Detail = "storing"
+	report(progress)
+
+	outputKey := cache.Key(videoID, cache.ArtifactAudio)
+
+	if err := storage.Upload(ctx, aa.store, outputKey, downloadPath); err != nil {
 		return nil, err
 	}
 
 	return &RetrieveAudioResult{
-		OutputPath: outputPath,
-		FileName:   fileName,
+		OutputKey: outputKey,
+		FileName:  videoID,
 	}, nil
 }
+
+// lastAttempt tells whether err ends the retries of the activity, because it
+// is not retryable or the attempts are used up.
+func lastAttempt(ctx context.Context, err error) bool {
+	var appErr *temporal.ApplicationError
+
+	if errors.As(err, &appErr) && appErr.NonRetryable() {
+		return true
+	}
+
+	return activity.GetInfo(ctx).Attempt >= DownloadAttempts
+}
+
+// canceledByWorkflow tells whether ctx was cancelled because the workflow
+// requested it, which the activity learns from a heartbeat, rather than by a
+// timeout or a worker shutdown.
+func canceledByWorkflow(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled) && temporal.IsCanceledError(context.Cause(ctx))
+}
+
+// resumableWorkDir returns the work dir of the previous attempt when it ran
+// on this host and its partial download is still there.
+func resumableWorkDir(ctx context.Context, hostname string) string {
+	if !activity.HasHeartbeatDetails(ctx) {
+		return ""
+	}
+
+	var previous DownloadProgress
+
+	if err := activity.GetHeartbeatDetails(ctx, &previous); err != nil {
+		return ""
+	}
+
+	if previous.Hostname != hostname || previous.WorkDir == "" {
+		return ""
+	}
+
+	if info, err := os.Stat(previous.WorkDir); err != nil || !info.IsDir() {
+		return ""
+	}
+
+	return previous.WorkDir
+}
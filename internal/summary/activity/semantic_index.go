@@ -0,0 +1,350 @@
+package activity
+
+import (
+	"api/internal/summary/cache"
+	"api/internal/summary/index"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"api/internal/summary/transcriber"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"go.temporal.io/sdk/activity"
+)
+
+// PassageTokenLimit is the estimated size of the passages transcripts and
+// summaries are cut into, short enough that a match points at one moment.
+const PassageTokenLimit = 250
+
+// embeddingBatchSize is how many passages are embedded per request.
+const embeddingBatchSize = 100
+
+// IndexActivities keep the semantic index of every processed video and
+// search it. The index is a local file, so they are registered only on the
+// index task queue, which a single worker serves.
+type IndexActivities struct {
+	openAIClient openai.Client
+	store        storage.Storage
+	index        *index.Index
+	// embeddingModel is the model passages and queries are embedded with.
+	embeddingModel string
+}
+
+func NewIndexActivities(
+	openAIClient openai.Client,
+	store storage.Storage,
+	idx *index.Index,
+	embeddingModel string,
+) *IndexActivities {
+	return &IndexActivities{
+		openAIClient,
+		store,
+		idx,
+		embeddingModel,
+	}
+}
+
+// Passage is a search result, with a link that opens the video at the
+// moment the passage starts when the source supports it.
+type Passage struct {
+	index.Match
+	// Timestamp is the start as hh:mm:ss, empty when it is unknown.
+	Timestamp string
+	Link      string
+}
+
+// IndexReport counts what IndexLibrary embedded. Videos and documents that
+// were already indexed in their current form are not counted.
+type IndexReport struct {
+	Videos   int
+	Passages int
+}
+
+// IndexVideo indexes the cached transcript of a video and its summary in a
+// style, by its style.Ref.CacheName, and returns how many passages it
+// embedded. Documents that did not change since they were indexed are kept.
+func (ia *IndexActivities) IndexVideo(ctx context.Context, videoID string, styleName string) (int, error) {
+	return ia.indexVideo(ctx, videoID, styleName)
+}
+
+// indexVideo is IndexVideo, heartbeating details while it embeds.
+func (ia *IndexActivities) indexVideo(ctx context.Context, videoID string, styleName string, details ...any) (int, error) {
+	var item source.Item
+
+	content, err := storage.ReadAll(ctx, ia.store, cache.Key(videoID, cache.ArtifactMetadata))
+
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, err
+	}
+
+	if err == nil {
+		// Without its metadata the video is still indexed, only untitled.
+		if err := json.Unmarshal(content, &item); err != nil {
+			activity.GetLogger(ctx).Warn("Failed to decode metadata", "VideoID", videoID, "Error", err)
+		}
+	}
+
+	passages := 0
+
+	transcript, err := loadTranscript(ctx, ia.store, videoID)
+
+	switch {
+	case err == nil:
+		n, err := ia.indexDocument(ctx, videoID, index.KindTranscript, "", item, transcript.Text, func() []index.Passage {
+			return transcriptPassages(transcript)
+		}, details...)
+
+		if err != nil {
+			return passages, err
+		}
+
+		passages += n
+	case !errors.Is(err, storage.ErrNotFound):
+		return passages, err
+	}
+
+	summary, err := storage.ReadAll(ctx, ia.store, cache.Key(videoID, cache.SummaryArtifact(styleName, "")))
+
+	switch {
+	case err == nil:
+		n, err := ia.indexDocument(ctx, videoID, index.KindSummary, styleName, item, string(summary), func() []index.Passage {
+			return summaryPassages(string(summary))
+		}, details...)
+
+		if err != nil {
+			return passages, err
+		}
+
+		passages += n
+	case !errors.Is(err, storage.ErrNotFound):
+		return passages, err
+	}
+
+	return passages, nil
+}
+
+// IndexLibrary indexes every video with cached metadata, which is every
+// video summarized so far, with its summary in the default style. It
+// heartbeats the report so far, which a retry continues from, as videos
+// indexed by an earlier attempt are skipped as unchanged.
+func (ia *IndexActivities) IndexLibrary(ctx context.Context) (IndexReport, error) {
+	var report IndexReport
+
+	if activity.HasHeartbeatDetails(ctx) {
+		activity.GetHeartbeatDetails(ctx, &report)
+	}
+
+	objects, err := ia.store.List(ctx, cache.Prefix(""))
+
+	if err != nil {
+		return report, err
+	}
+
+	for _, object := range objects {
+		if path.Base(object.Key) != string(cache.ArtifactMetadata) {
+			continue
+		}
+
+		activity.RecordHeartbeat(ctx, report)
+
+		passages, err := ia.indexVideo(ctx, path.Base(path.Dir(object.Key)), "", report)
+
+		if err != nil {
+			return report, err
+		}
+
+		if passages > 0 {
+			report.Videos++
+			report.Passages += passages
+		}
+	}
+
+	return report, nil
+}
+
+// FindPassages returns the limit passages across every indexed video that
+// are closest in meaning to query, best first.
+func (ia *IndexActivities) FindPassages(ctx context.Context, query string, limit int) ([]Passage, error) {
+	vectors, err := ia.embed(ctx, []string{query})
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := ia.index.Search(ctx, ia.embeddingModel, vectors[0], limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]Passage, len(matches))
+
+	for i, m := range matches {
+		found[i] = Passage{Match: m, Link: m.URL}
+
+		if m.Start < 0 {
+			continue
+		}
+
+		found[i].Timestamp = transcriber.FormatTimestamp(m.Start)
+
+		if supportsDeepLinks(m.URL) {
+			found[i].Link = DeepLink(m.URL, int(m.Start))
+		}
+	}
+
+	return found, nil
+}
+
+// indexDocument embeds the passages of a document unless it is indexed
+// with the same content and model already, and returns how many it
+// embedded.
+func (ia *IndexActivities) indexDocument(
+	ctx context.Context,
+	videoID string,
+	kind index.Kind,
+	style string,
+	item source.Item,
+	content string,
+	split func() []index.Passage,
+	details ...any,
+) (int, error) {
+	doc := index.Document{
+		VideoID: videoID,
+		Kind:    kind,
+		Style:   style,
+		Title:   item.Title,
+		URL:     item.Ref.Link(),
+		Hash:    fmt.Sprintf("%x", sha256.Sum256([]byte(ia.embeddingModel+"\n"+item.Title+"\n"+content))),
+		Model:   ia.embeddingModel,
+	}
+
+	hash, err := ia.index.Hash(ctx, videoID, kind, style)
+
+	if err != nil || hash == doc.Hash {
+		return 0, err
+	}
+
+	passages := split()
+	texts := make([]string, len(passages))
+
+	for i, p := range passages {
+		texts[i] = p.Text
+	}
+
+	vectors, err := ia.embed(ctx, texts, details...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range passages {
+		passages[i].Embedding = vectors[i]
+	}
+
+	return len(passages), ia.index.Replace(ctx, doc, passages)
+}
+
+// embed returns the embedding of each text, in order, heartbeating details
+// between batches.
+func (ia *IndexActivities) embed(ctx context.Context, texts []string, details ...any) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		activity.RecordHeartbeat(ctx, details...)
+
+		batch := texts[start:min(start+embeddingBatchSize, len(texts))]
+
+		resp, err := ia.openAIClient.Embeddings.New(ctx, openai.EmbeddingNewParams{
+			Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: batch},
+			Model: ia.embeddingModel,
+		})
+
+		if err != nil {
+			return nil, classify(err)
+		}
+
+		if len(resp.Data) != len(batch) {
+			return nil, fmt.Errorf("got %d embeddings for %d passages", len(resp.Data), len(batch))
+		}
+
+		for _, data := range resp.Data {
+			vector := make([]float32, len(data.Embedding))
+
+			for i, x := range data.Embedding {
+				vector[i] = float32(x)
+			}
+
+			vectors[start+int(data.Index)] = vector
+		}
+	}
+
+	return vectors, nil
+}
+
+// transcriptPassages cuts a transcript between segments, each passage
+// starting where its first segment does. Transcripts without timing have
+// passages without a start.
+func transcriptPassages(transcript transcriber.Transcript) []index.Passage {
+	passages := make([]index.Passage, 0)
+
+	for _, section := range transcript.Sections(PassageTokenLimit) {
+		p := index.Passage{Start: -1, Text: section.Text}
+
+		if len(transcript.Segments) > 0 {
+			p.Start = section.Segments[0].Start
+		}
+
+		passages = append(passages, p)
+	}
+
+	return passages
+}
+
+// summaryPassages cuts a summary at its headings, and sections too long for
+// one passage between their lines, each passage starting at the first
+// [hh:mm:ss] marker in it.
+func summaryPassages(summary string) []index.Passage {
+	sections := make([][]string, 0)
+	current := make([]string, 0)
+
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "#") && len(current) > 0 {
+			sections = append(sections, current)
+			current = make([]string, 0)
+		}
+
+		if line != "" {
+			current = append(current, line)
+		}
+	}
+
+	if len(current) > 0 {
+		sections = append(sections, current)
+	}
+
+	passages := make([]index.Passage, 0)
+
+	for _, section := range sections {
+		for _, group := range transcriber.GroupByTokens(section, PassageTokenLimit, 1) {
+			text := strings.Join(group, "\n")
+			p := index.Passage{Start: -1, Text: text}
+
+			if match := timestampPattern.FindStringSubmatch(text); match != nil {
+				p.Start = float64(parseTimestamp(match))
+			}
+
+			passages = append(passages, p)
+		}
+	}
+
+	return passages
+}
@@ -0,0 +1,300 @@
+package activity
+
+import (
+	"api/internal/summary/storage"
+	"api/internal/summary/transcriber"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// chunkSeconds keeps each chunk well below the Whisper API upload limit
+	// once re-encoded as 64kbps mono.
+	chunkSeconds = 600.0
+	// chunkOverlapSeconds is added on both sides of a cut so words spoken
+	// across a boundary are fully contained in at least one chunk.
+	chunkOverlapSeconds = 2.0
+	// silenceWindowSeconds is how far from the ideal cut we look for silence.
+	silenceWindowSeconds = 60.0
+	// maxUploadBytes is the largest file sent to a transcriber unsplit.
+	maxUploadBytes = 24 << 20
+)
+
+type AudioChunk struct {
+	Index int
+	Key   string
+	// Offset is where the chunk starts in the source audio.
+	Offset float64
+	// OwnedStart and OwnedEnd delimit the part of the source audio this chunk
+	// is responsible for, excluding the overlap shared with its neighbours.
+	OwnedStart float64
+	OwnedEnd   float64
+}
+
+var silenceEndPattern = regexp.MustCompile(`silence_end: ([0-9.]+) \| silence_duration: ([0-9.]+)`)
+
+// SplitAudio cuts the audio stored under audioKey into chunks stored next to
+// it, apart from those of other runs split by runID. Short audio is returned
+// as a single chunk pointing at audioKey itself.
+func (aa *ArtifactActivities) SplitAudio(ctx context.Context, audioKey string, runID string) ([]AudioChunk, error) {
+	filePath, cleanup, err := downloadToTemp(ctx, aa.store, audioKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer cleanup()
+
+	duration, err := probeDuration(ctx, filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if duration <= chunkSeconds && info.Size() <= maxUploadBytes {
+		return []AudioChunk{{
+			Index:      0,
+			Key:        audioKey,
+			Offset:     0,
+			OwnedStart: 0,
+			OwnedEnd:   duration,
+		}}, nil
+	}
+
+	silences, err := detectSilences(ctx, filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cuts := chooseCuts(duration, silences)
+	chunkPrefix := audioChunkPrefix(audioKey, runID)
+	chunks := make([]AudioChunk, 0, len(cuts)-1)
+
+	for i := 0; i < len(cuts)-1; i++ {
+		start := math.Max(cuts[i]-chunkOverlapSeconds, 0)
+		end := math.Min(cuts[i+1]+chunkOverlapSeconds, duration)
+		chunkName := fmt.Sprintf("%03d.mp3", i)
+		chunkPath := filepath.Join(filepath.Dir(filePath), chunkName)
+
+		cmd := exec.CommandContext(ctx, "ffmpeg",
+			"-y",
+			"-ss", formatSeconds(start),
+			"-t", formatSeconds(end-start),
+			"-i", filePath,
+			"-ac", "1",
+			"-b:a", "64k",
+			chunkPath,
+		)
+
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("ffmpeg failed to extract chunk %d: %w: %s", i, err, lastLine(out))
+		}
+
+		if err := storage.Upload(ctx, aa.store, chunkPrefix+chunkName, chunkPath); err != nil {
+			return nil, err
+		}
+
+		os.Remove(chunkPath)
+
+		chunks = append(chunks, AudioChunk{
+			Index:      i,
+			Key:        chunkPrefix + chunkName,
+			Offset:     start,
+			OwnedStart: cuts[i],
+			OwnedEnd:   cuts[i+1],
+		})
+	}
+
+	return chunks, nil
+}
+
+// RemoveAudioChunks deletes the chunks produced by SplitAudio. The source
+// audio is left untouched.
+func (aa *ArtifactActivities) RemoveAudioChunks(ctx context.Context, audioKey string, chunks []AudioChunk) error {
+	for _, chunk := range chunks {
+		if chunk.Key == audioKey {
+			continue
+		}
+
+		if err := aa.store.Delete(ctx, chunk.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveAllAudioChunks deletes every chunk a run stored for audioKey,
+// including those of a SplitAudio call that failed or was canceled halfway.
+// An empty runID deletes the chunks of every run.
+func (aa *ArtifactActivities) RemoveAllAudioChunks(ctx context.Context, audioKey string, runID string) error {
+	objects, err := aa.store.List(ctx, audioChunkPrefix(audioKey, runID))
+
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err := aa.store.Delete(ctx, object.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// audioChunkPrefix is where a run stores the chunks of audioKey, or for an
+// empty runID the chunks of every run.
+func audioChunkPrefix(audioKey string, runID string) string {
+	prefix := strings.TrimSuffix(audioKey, path.Ext(audioKey)) + "_chunks/"
+
+	if runID != "" {
+		prefix += runID + "/"
+	}
+
+	return prefix
+}
+
+// StitchTranscripts merges per-chunk transcripts into one transcript with
+// timestamps relative to the source audio. Segments in the overlap between
+// two chunks are kept only by the chunk that owns their midpoint. The
+// language is the one detected in the chunks with the most speech.
+func StitchTranscripts(chunks []AudioChunk, transcripts []transcriber.Transcript) transcriber.Transcript {
+	segments := make([]transcriber.Segment, 0)
+	speech := make(map[string]float64)
+
+	for i, chunk := range chunks {
+		for _, s := range transcripts[i].Segments {
+			start := s.Start + chunk.Offset
+			end := s.End + chunk.Offset
+			mid := (start + end) / 2
+
+			if mid < chunk.OwnedStart || (mid >= chunk.OwnedEnd && i < len(chunks)-1) {
+				continue
+			}
+
+			segments = append(segments, transcriber.Segment{
+				Start: start,
+				End:   end,
+				Text:  s.Text,
+			})
+
+			if transcripts[i].Language != "" {
+				speech[transcripts[i].Language] += end - start
+			}
+		}
+	}
+
+	transcript := transcriber.NewTranscript(segments)
+
+	for language, seconds := range speech {
+		if seconds > speech[transcript.Language] || (seconds == speech[transcript.Language] && language < transcript.Language) {
+			transcript.Language = language
+		}
+	}
+
+	return transcript
+}
+
+func probeDuration(ctx context.Context, filePath string) (float64, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "csv=p=0",
+		filePath,
+	)
+
+	out, err := cmd.Output()
+
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	return strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+}
+
+// detectSilences returns the midpoints of silent stretches in the audio.
+func detectSilences(ctx context.Context, filePath string) ([]float64, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", filePath,
+		"-af", "silencedetect=noise=-30dB:d=0.5",
+		"-f", "null",
+		"-",
+	)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg silence detection failed: %w: %s", err, lastLine(stderr.Bytes()))
+	}
+
+	silences := make([]float64, 0)
+
+	for _, match := range silenceEndPattern.FindAllStringSubmatch(stderr.String(), -1) {
+		end, _ := strconv.ParseFloat(match[1], 64)
+		length, _ := strconv.ParseFloat(match[2], 64)
+		silences = append(silences, end-length/2)
+	}
+
+	return silences, nil
+}
+
+// chooseCuts returns chunk boundaries, including 0 and duration. Each cut is
+// placed at the silence closest to the ideal chunk length, falling back to a
+// hard cut when there is no silence nearby.
+func chooseCuts(duration float64, silences []float64) []float64 {
+	cuts := []float64{0}
+
+	for {
+		last := cuts[len(cuts)-1]
+		target := last + chunkSeconds
+
+		if target >= duration-silenceWindowSeconds {
+			break
+		}
+
+		cut := target
+		bestDistance := silenceWindowSeconds
+
+		for _, s := range silences {
+			if s <= last {
+				continue
+			}
+
+			if d := math.Abs(s - target); d < bestDistance {
+				bestDistance = d
+				cut = s
+			}
+		}
+
+		cuts = append(cuts, cut)
+	}
+
+	return append(cuts, duration)
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,135 @@
+package activity
+
+import (
+	"api/internal/summary/transcriber"
+	"slices"
+	"testing"
+)
+
+func TestChooseCuts(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration float64
+		silences []float64
+		want     []float64
+	}{
+		{"shorter than a chunk", 500, nil, []float64{0, 500}},
+		{"no silences", 1000, nil, []float64{0, 600, 1000}},
+		{"closest silence", 1000, []float64{200, 580, 630}, []float64{0, 580, 1000}},
+		{"silence outside the window", 1000, []float64{539, 661}, []float64{0, 600, 1000}},
+		{"silence at the edge of the window", 1000, []float64{660}, []float64{0, 600, 1000}},
+		{"tie goes to the earlier silence", 1000, []float64{590, 610}, []float64{0, 590, 1000}},
+		{"silences before the last cut are ignored", 2000, []float64{1150}, []float64{0, 600, 1150, 1750, 2000}},
+		{"no short last chunk", 1250, nil, []float64{0, 600, 1250}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chooseCuts(tt.duration, tt.silences); !slices.Equal(got, tt.want) {
+				t.Errorf("chooseCuts(%v, %v) = %v, want %v", tt.duration, tt.silences, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStitchTranscripts(t *testing.T) {
+	// Two chunks cut at 600s, each overlapping the other by 2s.
+	chunks := []AudioChunk{
+		{Index: 0, Offset: 0, OwnedStart: 0, OwnedEnd: 600},
+		{Index: 1, Offset: 598, OwnedStart: 600, OwnedEnd: 1000},
+	}
+
+	transcripts := []transcriber.Transcript{
+		{
+			Language: "en",
+			Segments: []transcriber.Segment{
+				{Start: 0, End: 10, Text: "a"},
+				// Its midpoint, 598, is owned by the first chunk.
+				{Start: 595, End: 601, Text: "b"},
+				// Its midpoint is the cut, which the next chunk owns.
+				{Start: 599, End: 601, Text: "c"},
+			},
+		},
+		{
+			Language: "de",
+			Segments: []transcriber.Segment{
+				{Start: 0, End: 3, Text: "b"},
+				{Start: 1, End: 3, Text: "c"},
+				{Start: 10, End: 20, Text: "d"},
+				// The last chunk keeps segments ending past its end.
+				{Start: 401, End: 404, Text: "e"},
+			},
+		},
+	}
+
+	got := StitchTranscripts(chunks, transcripts)
+
+	want := []transcriber.Segment{
+		{Start: 0, End: 10, Text: "a"},
+		{Start: 595, End: 601, Text: "b"},
+		{Start: 599, End: 601, Text: "c"},
+		{Start: 608, End: 618, Text: "d"},
+		{Start: 999, End: 1002, Text: "e"},
+	}
+
+	if !slices.Equal(got.Segments, want) {
+		t.Errorf("segments = %v, want %v", got.Segments, want)
+	}
+
+	if got.Text != "a b c d e" {
+		t.Errorf("text = %q, want %q", got.Text, "a b c d e")
+	}
+
+	// 16s of English against 15s of German.
+	if got.Language != "en" {
+		t.Errorf("language = %q, want %q", got.Language, "en")
+	}
+}
+
+func TestStitchTranscriptsLanguage(t *testing.T) {
+	chunks := []AudioChunk{
+		{Index: 0, Offset: 0, OwnedStart: 0, OwnedEnd: 600},
+		{Index: 1, Offset: 598, OwnedStart: 600, OwnedEnd: 1200},
+		{Index: 2, Offset: 1198, OwnedStart: 1200, OwnedEnd: 1500},
+	}
+
+	segment := []transcriber.Segment{{Start: 10, End: 20, Text: "x"}}
+
+	tests := []struct {
+		name      string
+		languages []string
+		want      string
+	}{
+		{"tie goes to the first code", []string{"en", "de", ""}, "de"},
+		{"unknown languages are ignored", []string{"", "fr", ""}, "fr"},
+		{"all unknown", []string{"", "", ""}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcripts := make([]transcriber.Transcript, len(chunks))
+
+			for i, language := range tt.languages {
+				transcripts[i] = transcriber.Transcript{Language: language, Segments: segment}
+			}
+
+			if got := StitchTranscripts(chunks, transcripts); got.Language != tt.want {
+				t.Errorf("language = %q, want %q", got.Language, tt.want)
+			}
+		})
+	}
+}
+
+func TestStitchTranscriptsSingleChunk(t *testing.T) {
+	chunks := []AudioChunk{{Index: 0, Offset: 0, OwnedStart: 0, OwnedEnd: 300}}
+	segments := []transcriber.Segment{
+		{Start: 0, End: 150, Text: "first"},
+		{Start: 290, End: 310, Text: "past the probed end"},
+	}
+
+	got := StitchTranscripts(chunks, []transcriber.Transcript{{Segments: segments}})
+
+	if !slices.Equal(got.Segments, segments) {
+		t.Errorf("segments = %v, want %v", got.Segments, segments)
+	}
+}
@@ -0,0 +1,255 @@
+package activity
+
+import (
+	"api/internal/summary/cache"
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/youtube/v3"
+)
+
+// subscriptionsPrefix is where the state of channel subscriptions is stored.
+const subscriptionsPrefix = "subscriptions/"
+
+type ChannelUploads struct {
+	ChannelID string
+	Title     string
+	// Videos are ordered from the oldest to the newest upload.
+	Videos []shared.SearchResult
+}
+
+type NewVideos struct {
+	Videos []shared.SearchResult
+	// FirstPoll is set when nothing was processed for the subscription yet.
+	FirstPoll bool
+}
+
+// ListChannelUploads returns the latest maxVideos uploads of a channel given
+// by URL, handle or channel ID, using the YouTube Data API.
+func ListChannelUploads(ctx context.Context, channel string, maxVideos int) (*ChannelUploads, error) {
+	youtubeService, err := shared.NewYoutubeService(ctx)
+
+	if err != nil {
+		return nil, classify(fmt.Errorf("failed to create youtube service: %w", err))
+	}
+
+	call := youtubeService.Channels.List([]string{"snippet", "contentDetails"})
+
+	switch kind, value := parseChannel(channel); kind {
+	case "id":
+		call = call.Id(value)
+	case "username":
+		call = call.ForUsername(value)
+	case "custom":
+		channelID, err := customChannelID(ctx, value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		call = call.Id(channelID)
+	default:
+		call = call.ForHandle(value)
+	}
+
+	channels, err := call.Context(ctx).Do()
+
+	if err != nil {
+		return nil, classify(fmt.Errorf("failed to look up channel %s: %w", channel, err))
+	}
+
+	if len(channels.Items) == 0 {
+		return nil, classify(fmt.Errorf("%w: channel %s not found", source.ErrUnavailable, channel))
+	}
+
+	ch := channels.Items[0]
+
+	if maxVideos <= 0 || maxVideos > 50 {
+		maxVideos = 50
+	}
+
+	items, err := youtubeService.PlaylistItems.
+		List([]string{"snippet", "contentDetails"}).
+		PlaylistId(ch.ContentDetails.RelatedPlaylists.Uploads).
+		MaxResults(int64(maxVideos)).
+		Context(ctx).
+		Do()
+
+	if err != nil {
+		return nil, classify(fmt.Errorf("failed to list uploads of %s: %w", channel, err))
+	}
+
+	uploads := &ChannelUploads{
+		ChannelID: ch.Id,
+		Title:     ch.Snippet.Title,
+		Videos:    make([]shared.SearchResult, 0, len(items.Items)),
+	}
+
+	// The uploads playlist starts with the newest video.
+	for i := len(items.Items) - 1; i >= 0; i-- {
+		uploads.Videos = append(uploads.Videos, uploadResult(items.Items[i]))
+	}
+
+	return uploads, nil
+}
+
+func uploadResult(item *youtube.PlaylistItem) shared.SearchResult {
+	var r shared.SearchResult
+	r.Title = item.Snippet.Title
+	r.URL = fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.ContentDetails.VideoId)
+
+	return r
+}
+
+// parseChannel tells whether channel refers to a channel ID, a legacy
+// username, a custom URL or a handle, accepting both channel URLs and bare
+// values. Custom URLs are returned whole, as the API cannot look them up.
+func parseChannel(channel string) (kind string, value string) {
+	channel = strings.TrimSpace(channel)
+
+	if u, err := url.Parse(channel); err == nil && u.Host != "" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+		switch {
+		case len(parts) >= 2 && parts[0] == "channel":
+			return "id", parts[1]
+		case len(parts) >= 2 && parts[0] == "user":
+			return "username", parts[1]
+		case len(parts) >= 2 && parts[0] == "c":
+			return "custom", channel
+		default:
+			channel = parts[0]
+		}
+	}
+
+	if strings.HasPrefix(channel, "UC") && len(channel) == 24 {
+		return "id", channel
+	}
+
+	return "handle", strings.TrimPrefix(channel, "@")
+}
+
+// customChannelID resolves a /c/<name> channel URL, which is not always the
+// channel's handle, to its channel ID with yt-dlp.
+func customChannelID(ctx context.Context, channelURL string) (string, error) {
+	out, err := exec.CommandContext(ctx, "yt-dlp",
+		"--playlist-items", "1",
+		"--print", "channel_id",
+		// "--" ends the options, so a URL starting with "-" is not read as one.
+		"--", channelVideosURL(channelURL),
+	).Output()
+
+	if err != nil {
+		return "", classify(source.YtDlpError(fmt.Sprintf("yt-dlp failed to look up channel %s", channelURL), err, ""))
+	}
+
+	channelID, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+
+	if channelID == "" || channelID == "NA" {
+		return "", classify(fmt.Errorf("%w: channel %s not found", source.ErrUnavailable, channelURL))
+	}
+
+	return channelID, nil
+}
+
+// FilterProcessedVideos drops the videos already processed for the
+// subscription.
+func (aa *ArtifactActivities) FilterProcessedVideos(
+	ctx context.Context,
+	subscriptionID string,
+	videos []shared.SearchResult,
+) (*NewVideos, error) {
+	processed, err := aa.loadProcessedVideos(ctx, subscriptionID)
+
+	if errors.Is(err, storage.ErrNotFound) {
+		return &NewVideos{Videos: videos, FirstPoll: true}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	newVideos := &NewVideos{Videos: make([]shared.SearchResult, 0)}
+
+	for _, v := range videos {
+		if !processed[cache.VideoID(v.URL)] {
+			newVideos.Videos = append(newVideos.Videos, v)
+		}
+	}
+
+	return newVideos, nil
+}
+
+// MarkVideosProcessed remembers the videos so later polls of the
+// subscription skip them.
+func (aa *ArtifactActivities) MarkVideosProcessed(
+	ctx context.Context,
+	subscriptionID string,
+	videos []shared.SearchResult,
+) error {
+	processed, err := aa.loadProcessedVideos(ctx, subscriptionID)
+
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	if processed == nil {
+		processed = make(map[string]bool)
+	}
+
+	for _, v := range videos {
+		processed[cache.VideoID(v.URL)] = true
+	}
+
+	videoIDs := make([]string, 0, len(processed))
+
+	for id := range processed {
+		videoIDs = append(videoIDs, id)
+	}
+
+	sort.Strings(videoIDs)
+
+	content, err := json.Marshal(videoIDs)
+
+	if err != nil {
+		return err
+	}
+
+	return aa.store.Put(ctx, processedVideosKey(subscriptionID), bytes.NewReader(content))
+}
+
+func (aa *ArtifactActivities) loadProcessedVideos(ctx context.Context, subscriptionID string) (map[string]bool, error) {
+	content, err := storage.ReadAll(ctx, aa.store, processedVideosKey(subscriptionID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var videoIDs []string
+
+	if err := json.Unmarshal(content, &videoIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode processed videos of %s: %w", subscriptionID, err)
+	}
+
+	processed := make(map[string]bool, len(videoIDs))
+
+	for _, id := range videoIDs {
+		processed[id] = true
+	}
+
+	return processed, nil
+}
+
+func processedVideosKey(subscriptionID string) string {
+	return subscriptionsPrefix + subscriptionID + "/processed.json"
+}
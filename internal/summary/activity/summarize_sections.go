@@ -0,0 +1,112 @@
+package activity
+
+import (
+	"api/internal/summary/style"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+const (
+	// SinglePassTokenLimit is the largest transcript, in estimated tokens,
+	// summarized with a single prompt. Longer transcripts are summarized
+	// section by section and the section summaries merged afterwards.
+	SinglePassTokenLimit = 60000
+	// SectionTokenLimit is the size of each section in map-reduce mode.
+	SectionTokenLimit = 15000
+)
+
+func (apa *AudioProcessActivities) SummarizeTranscriptSection(
+	ctx context.Context,
+	section string,
+	part int,
+	parts int,
+	language string,
+	ref style.Ref,
+) (string, error) {
+	s, err := apa.styleFor(ref)
+
+	if err != nil {
+		return "", err
+	}
+
+	data := promptData(section, language)
+	data.Part, data.Parts = part, parts
+
+	prompt, err := s.Sections(data)
+
+	if err != nil {
+		return "", nonRetryable(ErrTypeInvalidRequest, err)
+	}
+
+	return apa.complete(ctx, prompt)
+}
+
+func (apa *AudioProcessActivities) MergeSectionSummaries(
+	ctx context.Context,
+	summaries []string,
+	language string,
+	ref style.Ref,
+) (string, error) {
+	s, err := apa.styleFor(ref)
+
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	for i, s := range summaries {
+		fmt.Fprintf(&b, "--- Part %d ---\n%s\n\n", i+1, s)
+	}
+
+	prompt, err := s.Merge(promptData(b.String(), language))
+
+	if err != nil {
+		return "", nonRetryable(ErrTypeInvalidRequest, err)
+	}
+
+	return apa.complete(ctx, prompt)
+}
+
+func (apa *AudioProcessActivities) complete(ctx context.Context, prompt string) (string, error) {
+	return apa.completeMessages(ctx, openai.UserMessage(prompt))
+}
+
+// completeMessages is complete for a conversation rather than a single
+// prompt.
+func (apa *AudioProcessActivities) completeMessages(
+	ctx context.Context,
+	messages ...openai.ChatCompletionMessageParamUnion,
+) (string, error) {
+	params := openai.ChatCompletionNewParams{
+		Messages: messages,
+		Model:    apa.summaryModel,
+		Seed:     openai.Int(0),
+	}
+
+	completion, err := apa.opanAPIClient.Chat.Completions.New(ctx, params)
+
+	if err != nil {
+		return "", classify(err)
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", errors.New("the model returned no answer")
+	}
+
+	choice := completion.Choices[0]
+
+	if choice.Message.Refusal != "" {
+		return "", contentPolicyError(choice.Message.Refusal)
+	}
+
+	if choice.FinishReason == "content_filter" {
+		return "", contentPolicyError("the answer was blocked by the content filter")
+	}
+
+	return choice.Message.Content, nil
+}
@@ -0,0 +1,81 @@
+package activity
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const timestampInstructions = `Each line of the text starts with a [hh:mm:ss] timestamp of the moment it was said. End every heading and every key point with the [hh:mm:ss] timestamp of the moment in the text it refers to.`
+
+// timestampPattern matches [hh:mm:ss] or [mm:ss] markers. The optional
+// trailing "(" identifies markers that are already markdown links.
+var timestampPattern = regexp.MustCompile(`\[(?:(\d{1,2}):)?(\d{1,2}):(\d{2})\](\()?`)
+
+// parseTimestamp returns the offset in seconds of a timestampPattern match.
+func parseTimestamp(match []string) int {
+	hours, _ := strconv.Atoi(match[1])
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+
+	return hours*3600 + minutes*60 + seconds
+}
+
+// LinkTimestamps turns the [hh:mm:ss] markers the model leaves in a summary
+// into markdown links that open the video at that moment. Summaries of
+// videos that don't support deep links keep the plain markers.
+func LinkTimestamps(summary string, videoURL string) string {
+	if !supportsDeepLinks(videoURL) {
+		return summary
+	}
+
+	return timestampPattern.ReplaceAllStringFunc(summary, func(marker string) string {
+		match := timestampPattern.FindStringSubmatch(marker)
+
+		if match[4] != "" {
+			return marker
+		}
+
+		offset := parseTimestamp(match)
+		label := strings.TrimSuffix(strings.TrimPrefix(marker, "["), "]")
+
+		return fmt.Sprintf("[%s](%s)", label, DeepLink(videoURL, offset))
+	})
+}
+
+// DeepLink returns videoURL with a "t" parameter that starts playback at the
+// given offset in seconds.
+func DeepLink(videoURL string, offset int) string {
+	u, err := url.Parse(videoURL)
+
+	if err != nil {
+		return videoURL
+	}
+
+	q := u.Query()
+	q.Del("t")
+
+	query := q.Encode()
+
+	if query != "" {
+		query += "&"
+	}
+
+	u.RawQuery = fmt.Sprintf("%st=%ds", query, offset)
+
+	return u.String()
+}
+
+func supportsDeepLinks(videoURL string) bool {
+	u, err := url.Parse(videoURL)
+
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+
+	return host == "youtube.com" || host == "m.youtube.com" || host == "youtu.be"
+}
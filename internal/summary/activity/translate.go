@@ -0,0 +1,30 @@
+package activity
+
+import (
+	"api/internal/summary/language"
+	"context"
+	"fmt"
+)
+
+// languageInstructions makes summaries come out in the spoken language of
+// the transcript rather than whichever language the model picks.
+func languageInstructions(code string) string {
+	if code == "" {
+		return "Write in the same language as the text."
+	}
+
+	return fmt.Sprintf("The text is in %s, write in %s as well.", language.Name(code), language.Name(code))
+}
+
+// TranslateSummary translates a markdown summary into the language with the
+// given ISO 639-1 code, keeping its structure and timestamps.
+func (apa *AudioProcessActivities) TranslateSummary(
+	ctx context.Context,
+	summary string,
+	code string,
+) (string, error) {
+	prompt := fmt.Sprintf(`Translate the following markdown summary into %s. Keep the markdown structure, headings, lists and links exactly as they are, only translate the text. Keep every [hh:mm:ss] timestamp unchanged and in the same place. Send only the translation without any other comments from you, and don't wrap your answer in "'''markdown'''". Summary: %s`,
+		language.Name(code), summary)
+
+	return apa.complete(ctx, prompt)
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type Artifact string
+
+const (
+	ArtifactAudio      Artifact = "audio.mp3"
+	ArtifactTranscript Artifact = "transcript.json"
+	ArtifactSummary    Artifact = "summary.md"
+	// ArtifactMetadata describes what was summarized, see source.Item.
+	ArtifactMetadata Artifact = "metadata.json"
+)
+
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// VideoID returns a stable cache key for a video URL. YouTube links in any of
+// their forms map to "youtube-<id>", anything else to a hash of the URL.
+func VideoID(videoURL string) string {
+	if id := youtubeID(videoURL); id != "" {
+		return "youtube-" + id
+	}
+
+	return fmt.Sprintf("url-%x", sha256.Sum256([]byte(strings.TrimSpace(videoURL))))[:20]
+}
+
+func youtubeID(videoURL string) string {
+	u, err := url.Parse(strings.TrimSpace(videoURL))
+
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	path := strings.Trim(u.Path, "/")
+	id := ""
+
+	switch host {
+	case "youtu.be":
+		id = path
+	case "youtube.com", "m.youtube.com", "music.youtube.com":
+		if path == "watch" {
+			id = u.Query().Get("v")
+			break
+		}
+
+		for _, prefix := range []string{"shorts/", "embed/", "live/", "v/"} {
+			if strings.HasPrefix(path, prefix) {
+				id = strings.TrimPrefix(path, prefix)
+			}
+		}
+	}
+
+	if !youtubeIDPattern.MatchString(id) {
+		return ""
+	}
+
+	return id
+}
+
+func Prefix(videoID string) string {
+	return path.Join("cache", videoID) + "/"
+}
+
+// Key returns the storage key of a cached artifact.
+func Key(videoID string, artifact Artifact) string {
+	return Prefix(videoID) + string(artifact)
+}
+
+// SummaryArtifact is the cached summary written in a style, by its
+// style.Ref.CacheName, and translated into a language, by ISO 639-1 code.
+// Both are optional, without either it is ArtifactSummary.
+func SummaryArtifact(style string, language string) Artifact {
+	name := "summary"
+
+	for _, part := range []string{style, language} {
+		if part != "" {
+			name += "." + part
+		}
+	}
+
+	return Artifact(name + ".md")
+}
+
+// IsSummary reports whether artifact is a SummaryArtifact.
+func IsSummary(artifact Artifact) bool {
+	return artifact == ArtifactSummary || strings.HasPrefix(string(artifact), "summary.") && strings.HasSuffix(string(artifact), ".md")
+}
@@ -0,0 +1,297 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Kind is what a passage was cut from.
+type Kind string
+
+const (
+	KindTranscript Kind = "transcript"
+	KindSummary    Kind = "summary"
+)
+
+// Document is a transcript or summary of one video, indexed as passages.
+type Document struct {
+	VideoID string
+	Kind    Kind
+	Title   string
+	URL     string
+	// Style is the style.Ref.CacheName of a summary, so summaries of each
+	// style are indexed side by side. It is empty for transcripts.
+	Style string
+	// Hash identifies the content and embedding model the passages were
+	// made from, so unchanged documents are not embedded again.
+	Hash string
+	// Model is the embedding model. Only passages embedded with the model
+	// of the query are searched.
+	Model string
+}
+
+type Passage struct {
+	// Start is the offset in seconds of the moment the passage starts at,
+	// negative when it is unknown.
+	Start     float64
+	Text      string
+	Embedding []float32
+}
+
+// Match is a passage found by Search.
+type Match struct {
+	VideoID string
+	Kind    Kind
+	Title   string
+	URL     string
+	Start   float64
+	Text    string
+	// Score is the cosine similarity of the passage to the query.
+	Score float64
+}
+
+// Index is a vector store of passages in a SQLite database. Vectors are
+// compared one by one on search, which is fast enough for the few hundred
+// thousand passages a personal library reaches.
+type Index struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS documents (
+	video_id   TEXT NOT NULL,
+	kind       TEXT NOT NULL,
+	style      TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	url        TEXT NOT NULL,
+	hash       TEXT NOT NULL,
+	model      TEXT NOT NULL,
+	indexed_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (video_id, kind, style)
+);
+
+CREATE TABLE IF NOT EXISTS passages (
+	video_id  TEXT NOT NULL,
+	kind      TEXT NOT NULL,
+	style     TEXT NOT NULL,
+	position  INTEGER NOT NULL,
+	start     REAL NOT NULL,
+	text      TEXT NOT NULL,
+	embedding BLOB NOT NULL,
+	PRIMARY KEY (video_id, kind, style, position)
+);
+`
+
+// Open opens the index at filePath, creating it if needed.
+func Open(filePath string) (*Index, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", filePath))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index: %w", err)
+	}
+
+	// SQLite allows one writer at a time, and activities index in parallel.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create index schema: %w", err)
+	}
+
+	return &Index{db}, nil
+}
+
+func (idx *Index) Close() error {
+	return idx.db.Close()
+}
+
+// Hash returns the hash the document was last indexed with, empty when it
+// is not indexed.
+func (idx *Index) Hash(ctx context.Context, videoID string, kind Kind, style string) (string, error) {
+	var hash string
+
+	err := idx.db.QueryRowContext(
+		ctx,
+		`SELECT hash FROM documents WHERE video_id = ? AND kind = ? AND style = ?`,
+		videoID,
+		kind,
+		style,
+	).Scan(&hash)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return hash, err
+}
+
+// Replace stores the passages of a document in place of those it had.
+func (idx *Index) Replace(ctx context.Context, doc Document, passages []Passage) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM passages WHERE video_id = ? AND kind = ? AND style = ?`, doc.VideoID, doc.Kind, doc.Style); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT OR REPLACE INTO documents (video_id, kind, style, title, url, hash, model, indexed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		doc.VideoID,
+		doc.Kind,
+		doc.Style,
+		doc.Title,
+		doc.URL,
+		doc.Hash,
+		doc.Model,
+		time.Now(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	insert, err := tx.PrepareContext(ctx, `INSERT INTO passages (video_id, kind, style, position, start, text, embedding) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+
+	if err != nil {
+		return err
+	}
+
+	defer insert.Close()
+
+	for i, p := range passages {
+		if _, err := insert.ExecContext(ctx, doc.VideoID, doc.Kind, doc.Style, i, p.Start, p.Text, encode(normalize(p.Embedding))); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Search returns the limit passages embedded with model that are closest to
+// the query vector, best first.
+func (idx *Index) Search(ctx context.Context, model string, query []float32, limit int) ([]Match, error) {
+	rows, err := idx.db.QueryContext(ctx, `
+		SELECT p.video_id, p.kind, d.title, d.url, p.start, p.text, p.embedding
+		FROM passages p JOIN documents d ON d.video_id = p.video_id AND d.kind = p.kind AND d.style = p.style
+		WHERE d.model = ?`,
+		model,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	query = normalize(query)
+	matches := make([]Match, 0)
+
+	for rows.Next() {
+		var m Match
+		var embedding []byte
+
+		if err := rows.Scan(&m.VideoID, &m.Kind, &m.Title, &m.URL, &m.Start, &m.Text, &embedding); err != nil {
+			return nil, err
+		}
+
+		m.Score = dot(query, decode(embedding))
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// normalize scales v to unit length, so the dot product of two vectors is
+// their cosine similarity.
+func normalize(v []float32) []float32 {
+	var sum float64
+
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+
+	if sum == 0 {
+		return v
+	}
+
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(v))
+
+	for i, x := range v {
+		out[i] = x / norm
+	}
+
+	return out
+}
+
+func dot(a []float32, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var sum float64
+
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+
+	return sum
+}
+
+func encode(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+
+	return b
+}
+
+func decode(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+
+	return v
+}
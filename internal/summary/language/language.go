@@ -0,0 +1,117 @@
+package language
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// names are the English names of the languages Whisper transcribes, by
+// ISO 639-1 code.
+var names = map[string]string{
+	"af": "Afrikaans",
+	"ar": "Arabic",
+	"hy": "Armenian",
+	"az": "Azerbaijani",
+	"be": "Belarusian",
+	"bs": "Bosnian",
+	"bg": "Bulgarian",
+	"ca": "Catalan",
+	"zh": "Chinese",
+	"hr": "Croatian",
+	"cs": "Czech",
+	"da": "Danish",
+	"nl": "Dutch",
+	"en": "English",
+	"et": "Estonian",
+	"fi": "Finnish",
+	"fr": "French",
+	"gl": "Galician",
+	"de": "German",
+	"el": "Greek",
+	"he": "Hebrew",
+	"hi": "Hindi",
+	"hu": "Hungarian",
+	"is": "Icelandic",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"kn": "Kannada",
+	"kk": "Kazakh",
+	"ko": "Korean",
+	"lv": "Latvian",
+	"lt": "Lithuanian",
+	"mk": "Macedonian",
+	"ms": "Malay",
+	"mr": "Marathi",
+	"mi": "Maori",
+	"ne": "Nepali",
+	"no": "Norwegian",
+	"fa": "Persian",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ro": "Romanian",
+	"ru": "Russian",
+	"sr": "Serbian",
+	"sk": "Slovak",
+	"sl": "Slovenian",
+	"es": "Spanish",
+	"sw": "Swahili",
+	"sv": "Swedish",
+	"tl": "Tagalog",
+	"ta": "Tamil",
+	"th": "Thai",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"ur": "Urdu",
+	"vi": "Vietnamese",
+	"cy": "Welsh",
+}
+
+// Code returns the ISO 639-1 code of a language given by code or English
+// name, in any case, or "" when the language is unknown.
+func Code(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if _, ok := names[s]; ok {
+		return s
+	}
+
+	for code, name := range names {
+		if strings.ToLower(name) == s {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// Name returns the English name of a language code, or the code itself when
+// it is unknown.
+func Name(code string) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+
+	return code
+}
+
+// Parse turns language codes or names into distinct codes, keeping their
+// order.
+func Parse(languages []string) ([]string, error) {
+	codes := make([]string, 0, len(languages))
+
+	for _, l := range languages {
+		code := Code(l)
+
+		if code == "" {
+			return nil, fmt.Errorf("unknown language %q", l)
+		}
+
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes, nil
+}
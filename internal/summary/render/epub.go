@@ -0,0 +1,144 @@
+package render
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/gomarkdown/markdown/html"
+)
+
+// EPUBRenderer produces a single-chapter EPUB 3 book.
+type EPUBRenderer struct{}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var epubTemplates = template.Must(template.New("epub").Funcs(template.FuncMap{
+	"xml": xmlEscape,
+}).Parse(`
+{{define "content.opf"}}<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{.ID}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:language>{{xml .Language}}</dc:language>
+    {{if .URL}}<dc:source>{{xml .URL}}</dc:source>{{end}}
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="summary" href="summary.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="summary"/>
+  </spine>
+</package>
+{{end}}
+{{define "nav.xhtml"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>{{xml .Title}}</title></head>
+<body>
+  <nav epub:type="toc">
+    <ol><li><a href="summary.xhtml">{{xml .Title}}</a></li></ol>
+  </nav>
+</body>
+</html>
+{{end}}
+{{define "summary.xhtml"}}<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>{{xml .Title}}</title></head>
+<body>
+<h1>{{xml .Title}}</h1>
+{{if .URL}}<p>Source: <a href="{{xml .URL}}">{{xml .URL}}</a></p>{{end}}
+{{.Body}}
+</body>
+</html>
+{{end}}
+`))
+
+func (EPUBRenderer) Render(ctx context.Context, doc Document) ([]byte, error) {
+	data := struct {
+		ID       string
+		Title    string
+		URL      string
+		Modified string
+		Language string
+		Body     string
+	}{
+		ID:       fmt.Sprintf("urn:sha1:%x", sha1.Sum([]byte(doc.URL+doc.Markdown))),
+		Title:    doc.title(),
+		URL:      doc.URL,
+		Modified: time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		Language: doc.language(),
+		Body:     string(markdownToHTML(doc.Markdown, html.UseXHTML)),
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	// The mimetype entry must come first and be stored uncompressed.
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := mimetype.Write([]byte("application/epub+zip")); err != nil {
+		return nil, err
+	}
+
+	if err := writeZipEntry(zw, "META-INF/container.xml", []byte(epubContainer)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"content.opf", "nav.xhtml", "summary.xhtml"} {
+		var page bytes.Buffer
+
+		if err := epubTemplates.ExecuteTemplate(&page, name, data); err != nil {
+			return nil, err
+		}
+
+		if err := writeZipEntry(zw, "OEBPS/"+name, page.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (EPUBRenderer) Extension() string   { return "epub" }
+func (EPUBRenderer) ContentType() string { return "application/epub+zip" }
+
+func writeZipEntry(zw *zip.Writer, name string, content []byte) error {
+	w, err := zw.Create(name)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
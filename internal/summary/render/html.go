@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+type HTMLRenderer struct{}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 46rem; margin: 2rem auto; padding: 0 1rem; font: 16px/1.6 system-ui, sans-serif; color: #1f2328; }
+h1, h2, h3 { line-height: 1.25; }
+a { color: #0969da; }
+code, pre { background: #f6f8fa; border-radius: 4px; }
+pre { padding: 1rem; overflow-x: auto; }
+.source { color: #59636e; font-size: 0.9rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .URL}}<p class="source">Source: <a href="{{.URL}}">{{.URL}}</a></p>{{end}}
+{{.Body}}
+</body>
+</html>
+`))
+
+func (HTMLRenderer) Render(ctx context.Context, doc Document) ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := pageTemplate.Execute(&buf, struct {
+		Title    string
+		URL      string
+		Language string
+		Body     template.HTML
+	}{
+		Title:    doc.title(),
+		URL:      doc.URL,
+		Language: doc.language(),
+		Body:     template.HTML(markdownToHTML(doc.Markdown, html.CommonFlags|html.HrefTargetBlank)),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (HTMLRenderer) Extension() string   { return "html" }
+func (HTMLRenderer) ContentType() string { return "text/html; charset=utf-8" }
+
+// markdownToHTML renders markdown written by a model from a transcript, so
+// neither may inject markup. Raw HTML is dropped and links are kept only for
+// safe protocols.
+func markdownToHTML(md string, flags html.Flags) []byte {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs)
+	renderer := html.NewRenderer(html.RendererOptions{Flags: flags | html.SkipHTML | html.Safelink})
+
+	return markdown.ToHTML([]byte(md), p, renderer)
+}
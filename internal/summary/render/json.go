@@ -0,0 +1,42 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+type JSONRenderer struct{}
+
+type jsonSummary struct {
+	Title    string `json:"title"`
+	URL      string `json:"url,omitempty"`
+	Language string `json:"language,omitempty"`
+	Markdown string `json:"markdown"`
+	HTML     string `json:"html"`
+}
+
+func (JSONRenderer) Render(ctx context.Context, doc Document) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(jsonSummary{
+		Title:    doc.title(),
+		URL:      doc.URL,
+		Language: doc.Language,
+		Markdown: doc.Markdown,
+		HTML:     string(markdownToHTML(doc.Markdown, 0)),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (JSONRenderer) Extension() string   { return "json" }
+func (JSONRenderer) ContentType() string { return "application/json; charset=utf-8" }
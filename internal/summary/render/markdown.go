@@ -0,0 +1,12 @@
+package render
+
+import "context"
+
+type MarkdownRenderer struct{}
+
+func (MarkdownRenderer) Render(ctx context.Context, doc Document) ([]byte, error) {
+	return []byte(doc.Markdown), nil
+}
+
+func (MarkdownRenderer) Extension() string   { return "md" }
+func (MarkdownRenderer) ContentType() string { return "text/markdown; charset=utf-8" }
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const defaultPDFBinary = "wkhtmltopdf"
+
+// PDFRenderer prints the HTML rendering of a summary to PDF with
+// wkhtmltopdf, or a compatible binary set in PDF_RENDERER_BINARY.
+type PDFRenderer struct {
+	binary string
+}
+
+func NewPDFRenderer() PDFRenderer {
+	binary := os.Getenv("PDF_RENDERER_BINARY")
+
+	if binary == "" {
+		binary = defaultPDFBinary
+	}
+
+	return PDFRenderer{binary: binary}
+}
+
+func (r PDFRenderer) Render(ctx context.Context, doc Document) ([]byte, error) {
+	page, err := HTMLRenderer{}.Render(ctx, doc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	workDir, err := os.MkdirTemp("", "summary-pdf-*")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(workDir)
+
+	htmlPath := filepath.Join(workDir, "summary.html")
+	pdfPath := filepath.Join(workDir, "summary.pdf")
+
+	if err := os.WriteFile(htmlPath, page, 0644); err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+
+	// The page holds model output, so it may neither run scripts nor embed
+	// files of the worker beyond its own.
+	cmd := exec.CommandContext(ctx, r.binary,
+		"--quiet",
+		"--encoding", "utf-8",
+		"--disable-javascript",
+		"--disable-local-file-access",
+		"--allow", workDir,
+		htmlPath,
+		pdfPath,
+	)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", r.binary, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return os.ReadFile(pdfPath)
+}
+
+func (PDFRenderer) Extension() string   { return "pdf" }
+func (PDFRenderer) ContentType() string { return "application/pdf" }
@@ -0,0 +1,87 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+	FormatEPUB     Format = "epub"
+	FormatPDF      Format = "pdf"
+)
+
+var Formats = []Format{FormatMarkdown, FormatHTML, FormatJSON, FormatEPUB, FormatPDF}
+
+// ParseFormat accepts a format name or file extension, case-insensitively.
+// An empty string selects markdown.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), ".")) {
+	case "", "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "json":
+		return FormatJSON, nil
+	case "epub":
+		return FormatEPUB, nil
+	case "pdf":
+		return FormatPDF, nil
+	default:
+		return "", fmt.Errorf("unsupported summary format %q", s)
+	}
+}
+
+// Document is everything a renderer needs to produce a summary file.
+type Document struct {
+	Title    string
+	URL      string
+	Markdown string
+	// Language is the ISO 639-1 code of the language the summary is written
+	// in, empty when it is unknown.
+	Language string
+}
+
+type Renderer interface {
+	Render(ctx context.Context, doc Document) ([]byte, error)
+	Extension() string
+	ContentType() string
+}
+
+func New(format Format) (Renderer, error) {
+	switch format {
+	case FormatMarkdown, "":
+		return MarkdownRenderer{}, nil
+	case FormatHTML:
+		return HTMLRenderer{}, nil
+	case FormatJSON:
+		return JSONRenderer{}, nil
+	case FormatEPUB:
+		return EPUBRenderer{}, nil
+	case FormatPDF:
+		return NewPDFRenderer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported summary format %q", format)
+	}
+}
+
+func (d Document) title() string {
+	if d.Title == "" {
+		return "Summary"
+	}
+
+	return d.Title
+}
+
+func (d Document) language() string {
+	if d.Language == "" {
+		return "en"
+	}
+
+	return d.Language
+}
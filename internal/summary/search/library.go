@@ -0,0 +1,183 @@
+package search
+
+import (
+	"api/internal/summary/cache"
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"api/internal/summary/storage"
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LibraryProvider searches the summaries we already have, so a topic that
+// was covered before can be answered from the cache.
+type LibraryProvider struct {
+	store      storage.Storage
+	maxResults int
+	mu         sync.Mutex
+	// entries holds the summaries read by earlier searches, by cache
+	// directory, so a search only reads the ones that changed since.
+	entries map[string]libraryEntry
+}
+
+// libraryEntry is a cached summary with its lower-cased title and text.
+type libraryEntry struct {
+	item             source.Item
+	title            string
+	text             string
+	metadataModified time.Time
+	summaryModified  time.Time
+}
+
+func NewLibraryProvider(store storage.Storage, maxResults int) *LibraryProvider {
+	return &LibraryProvider{
+		store,
+		maxResults,
+		sync.Mutex{},
+		make(map[string]libraryEntry),
+	}
+}
+
+func (lp *LibraryProvider) Name() string {
+	return string(BackendLibrary)
+}
+
+// Search ranks cached summaries by how many words of the topic appear in
+// their title and text, title matches weighing more. Videos whose metadata
+// or summary cannot be read are left out rather than failing the search.
+func (lp *LibraryProvider) Search(ctx context.Context, params shared.SearchParams) ([]shared.SearchResult, error) {
+	terms := make([]string, 0)
+
+	for _, term := range strings.Fields(strings.ToLower(params.Topic)) {
+		if len(term) > 2 {
+			terms = append(terms, term)
+		}
+	}
+
+	objects, err := lp.store.List(ctx, cache.Prefix(""))
+
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]storage.Object)
+
+	for _, object := range objects {
+		if path.Base(object.Key) == string(cache.ArtifactSummary) {
+			summaries[path.Dir(object.Key)] = object
+		}
+	}
+
+	type match struct {
+		item  source.Item
+		score int
+	}
+
+	matches := make([]match, 0)
+	entries := make(map[string]libraryEntry)
+
+	for _, object := range objects {
+		if path.Base(object.Key) != string(cache.ArtifactMetadata) {
+			continue
+		}
+
+		dir := path.Dir(object.Key)
+		summary, ok := summaries[dir]
+
+		if !ok {
+			continue
+		}
+
+		entry, ok := lp.entry(ctx, dir, object, summary)
+
+		if !ok {
+			continue
+		}
+
+		entries[dir] = entry
+		score := 0
+
+		for _, term := range terms {
+			if strings.Contains(entry.title, term) {
+				score += 3
+			}
+
+			if strings.Contains(entry.text, term) {
+				score++
+			}
+		}
+
+		if score > 0 {
+			matches = append(matches, match{entry.item, score})
+		}
+	}
+
+	lp.mu.Lock()
+	lp.entries = entries
+	lp.mu.Unlock()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	results := make([]shared.SearchResult, 0, lp.maxResults)
+
+	for _, m := range matches {
+		if len(results) == lp.maxResults {
+			break
+		}
+
+		var r shared.SearchResult
+		r.Title = m.item.Title
+		r.URL = m.item.Ref.String()
+		r.Source = m.item.Ref
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// entry returns the summary in dir, read again only when its metadata or
+// summary object changed since the last search. It reports false when either
+// cannot be read.
+func (lp *LibraryProvider) entry(ctx context.Context, dir string, metadata, summary storage.Object) (libraryEntry, bool) {
+	lp.mu.Lock()
+	cached, ok := lp.entries[dir]
+	lp.mu.Unlock()
+
+	if ok && cached.metadataModified.Equal(metadata.LastModified) && cached.summaryModified.Equal(summary.LastModified) {
+		return cached, true
+	}
+
+	content, err := storage.ReadAll(ctx, lp.store, metadata.Key)
+
+	if err != nil {
+		return libraryEntry{}, false
+	}
+
+	var item source.Item
+
+	if err := json.Unmarshal(content, &item); err != nil {
+		return libraryEntry{}, false
+	}
+
+	text, err := storage.ReadAll(ctx, lp.store, summary.Key)
+
+	if err != nil {
+		return libraryEntry{}, false
+	}
+
+	return libraryEntry{
+		item,
+		strings.ToLower(item.Title),
+		strings.ToLower(string(text)),
+		metadata.LastModified,
+		summary.LastModified,
+	}, true
+}
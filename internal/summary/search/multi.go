@@ -0,0 +1,91 @@
+package search
+
+import (
+	"api/internal/summary/shared"
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+// MultiProvider queries several providers concurrently and merges their
+// results into one list, each tagged with the provider it came from.
+type MultiProvider struct {
+	providers []shared.SearchProvider
+}
+
+func NewMultiProvider(providers ...shared.SearchProvider) *MultiProvider {
+	return &MultiProvider{
+		providers,
+	}
+}
+
+func (mp *MultiProvider) Name() string {
+	return "multi"
+}
+
+// Search interleaves the results of all providers, so the first results
+// show every origin, and drops results pointing at the same source. A
+// failing provider is skipped unless every provider fails.
+func (mp *MultiProvider) Search(ctx context.Context, params shared.SearchParams) ([]shared.SearchResult, error) {
+	results := make([][]shared.SearchResult, len(mp.providers))
+	errs := make([]error, len(mp.providers))
+
+	var wg sync.WaitGroup
+
+	for i, provider := range mp.providers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i], errs[i] = provider.Search(ctx, params)
+
+			for j := range results[i] {
+				results[i][j].Origin = provider.Name()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	failed := 0
+
+	for i, err := range errs {
+		if err != nil {
+			log.Printf("Search provider %s failed: %v", mp.providers[i].Name(), err)
+			failed++
+		}
+	}
+
+	if failed > 0 && failed == len(mp.providers) {
+		return nil, errors.Join(errs...)
+	}
+
+	merged := make([]shared.SearchResult, 0)
+	seen := make(map[string]bool)
+
+	for i := 0; ; i++ {
+		added := false
+
+		for _, providerResults := range results {
+			if i >= len(providerResults) {
+				continue
+			}
+
+			added = true
+			id := providerResults[i].Ref().ID()
+
+			if !seen[id] {
+				seen[id] = true
+				merged = append(merged, providerResults[i])
+			}
+		}
+
+		if !added {
+			break
+		}
+	}
+
+	return merged, nil
+}
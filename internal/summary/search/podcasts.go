@@ -0,0 +1,131 @@
+package search
+
+import (
+	"api/internal/summary/shared"
+	"api/internal/summary/source"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const podcastSearchURL = "https://itunes.apple.com/search"
+
+// PodcastProvider searches podcast episodes in the Apple Podcasts directory,
+// which needs no API key. Results point at the episode in its RSS feed.
+type PodcastProvider struct {
+	maxResults int
+}
+
+type podcastSearchResponse struct {
+	Results []struct {
+		TrackName       string    `json:"trackName"`
+		CollectionName  string    `json:"collectionName"`
+		TrackViewURL    string    `json:"trackViewUrl"`
+		FeedURL         string    `json:"feedUrl"`
+		EpisodeGUID     string    `json:"episodeGuid"`
+		EpisodeURL      string    `json:"episodeUrl"`
+		TrackTimeMillis int64     `json:"trackTimeMillis"`
+		ReleaseDate     time.Time `json:"releaseDate"`
+	} `json:"results"`
+}
+
+func NewPodcastProvider(maxResults int) *PodcastProvider {
+	return &PodcastProvider{
+		maxResults,
+	}
+}
+
+func (pp *PodcastProvider) Name() string {
+	return string(BackendPodcasts)
+}
+
+// Search applies the same duration filter and date ordering as YouTube
+// search, other orders keep the directory's relevance ranking.
+func (pp *PodcastProvider) Search(ctx context.Context, params shared.SearchParams) ([]shared.SearchResult, error) {
+	query := url.Values{}
+	query.Set("term", params.Topic)
+	query.Set("media", "podcast")
+	query.Set("entity", "podcastEpisode")
+	// Ask for more than needed, as the duration filter drops some.
+	query.Set("limit", strconv.Itoa(pp.maxResults*4))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, podcastSearchURL+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("podcast search failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("podcast search failed: %s", resp.Status)
+	}
+
+	var body podcastSearchResponse
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode podcast search results: %w", err)
+	}
+
+	episodes := body.Results[:0]
+
+	for _, e := range body.Results {
+		if e.FeedURL != "" && matchesDuration(time.Duration(e.TrackTimeMillis)*time.Millisecond, params.Duration) {
+			episodes = append(episodes, e)
+		}
+	}
+
+	if params.Sort_BY == "date" {
+		sort.SliceStable(episodes, func(i, j int) bool {
+			return episodes[i].ReleaseDate.After(episodes[j].ReleaseDate)
+		})
+	}
+
+	results := make([]shared.SearchResult, 0, pp.maxResults)
+
+	for _, e := range episodes {
+		if len(results) == pp.maxResults {
+			break
+		}
+
+		episode := e.EpisodeGUID
+
+		if episode == "" {
+			episode = e.EpisodeURL
+		}
+
+		var r shared.SearchResult
+		r.Title = fmt.Sprintf("%s: %s", e.CollectionName, e.TrackName)
+		r.URL = e.TrackViewURL
+		r.Source = source.FromPodcast(e.FeedURL, episode)
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// matchesDuration implements the YouTube videoDuration filter values.
+func matchesDuration(d time.Duration, filter string) bool {
+	switch filter {
+	case "short":
+		return d < 4*time.Minute
+	case "medium":
+		return d >= 4*time.Minute && d <= 20*time.Minute
+	case "long":
+		return d > 20*time.Minute
+	default:
+		return true
+	}
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"api/internal/summary/shared"
+	"api/internal/summary/storage"
+	"fmt"
+)
+
+type Backend string
+
+const (
+	BackendYoutube  Backend = "youtube"
+	BackendPodcasts Backend = "podcasts"
+	BackendLibrary  Backend = "library"
+)
+
+type Config struct {
+	Backends []Backend `yaml:"providers"`
+	// MaxResults is how many results each backend contributes.
+	MaxResults int `yaml:"max_results"`
+}
+
+// New returns a provider that queries every configured backend and merges
+// their results. The library backend reads summaries from store.
+func New(cfg Config, store storage.Storage) (shared.SearchProvider, error) {
+	providers := make([]shared.SearchProvider, 0, len(cfg.Backends))
+
+	for _, backend := range cfg.Backends {
+		switch backend {
+		case BackendYoutube:
+			providers = append(providers, NewYoutubeProvider(cfg.MaxResults))
+		case BackendPodcasts:
+			providers = append(providers, NewPodcastProvider(cfg.MaxResults))
+		case BackendLibrary:
+			providers = append(providers, NewLibraryProvider(store, cfg.MaxResults))
+		default:
+			return nil, fmt.Errorf("unknown search provider %q", backend)
+		}
+	}
+
+	return NewMultiProvider(providers...), nil
+}
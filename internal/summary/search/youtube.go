@@ -0,0 +1,62 @@
+package search
+
+import (
+	"api/internal/summary/shared"
+	"context"
+	"fmt"
+)
+
+// YoutubeProvider searches YouTube videos with the YouTube Data API.
+type YoutubeProvider struct {
+	maxResults int
+}
+
+func NewYoutubeProvider(maxResults int) *YoutubeProvider {
+	return &YoutubeProvider{
+		maxResults,
+	}
+}
+
+func (yp *YoutubeProvider) Name() string {
+	return string(BackendYoutube)
+}
+
+func (yp *YoutubeProvider) Search(ctx context.Context, params shared.SearchParams) ([]shared.SearchResult, error) {
+	youtubeService, err := shared.NewYoutubeService(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create youtube service: %w", err)
+	}
+
+	call := youtubeService.Search.
+		List([]string{"id", "snippet"}).
+		Q(params.Topic).
+		Type("video").
+		MaxResults(int64(yp.maxResults))
+
+	if params.Duration != "" {
+		call = call.VideoDuration(params.Duration)
+	}
+
+	if params.Sort_BY != "" {
+		call = call.Order(params.Sort_BY)
+	}
+
+	res, err := call.Context(ctx).Do()
+
+	if err != nil {
+		return nil, fmt.Errorf("youtube search failed: %w", err)
+	}
+
+	results := make([]shared.SearchResult, 0)
+
+	for _, item := range res.Items {
+		var r shared.SearchResult
+		r.Title = item.Snippet.Title
+		r.URL = fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.Id.VideoId)
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
@@ -2,7 +2,6 @@ package shared
 
 import (
 	"github.com/nlpodyssey/openai-agents-go/agents"
-	"github.com/openai/openai-go"
 )
 
 const REFINE_AGENT_INSTRUCTIONS = `
@@ -35,9 +34,9 @@ const REFINE_AGENT_INSTRUCTIONS = `
 	- Make sure to gather all the information needed to carry out the search task in a concise, well-structured manner. Use bullet points or numbered lists if appropriate for clarity. Don't ask for unnecessary information, or information that the user has already provided.
 `
 
-func NewRefineAgent() *agents.Agent {
+func NewRefineAgent(model string) *agents.Agent {
 	return agents.New("Refine agent").
 		WithInstructions(REFINE_AGENT_INSTRUCTIONS).
-		WithModel(openai.ChatModelGPT4oMini).
+		WithModel(model).
 		WithOutputType(agents.OutputType[WithRefineOutput]())
 }
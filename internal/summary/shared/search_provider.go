@@ -0,0 +1,11 @@
+package shared
+
+import "context"
+
+// SearchProvider is a backend of the search agent's tool, such as YouTube or
+// the library of summaries we already have.
+type SearchProvider interface {
+	// Name is the origin the provider's results are tagged with.
+	Name() string
+	Search(ctx context.Context, params SearchParams) ([]SearchResult, error)
+}
@@ -2,7 +2,6 @@ package shared
 
 import (
 	"github.com/nlpodyssey/openai-agents-go/agents"
-	"github.com/openai/openai-go"
 )
 
 const TRIAGE_AGENT_INSTRUCTIONS = `
@@ -24,11 +23,11 @@ const TRIAGE_AGENT_INSTRUCTIONS = `
 	Return exactly ONE function-call.
 	`
 
-func NewTriageAgent() *agents.Agent {
-	refine_agent := NewRefineAgent()
-	search_agent := NewSearchAgent()
+func NewTriageAgent(provider SearchProvider, model string) *agents.Agent {
+	refine_agent := NewRefineAgent(model)
+	search_agent := NewSearchAgent(provider, model)
 	return agents.New("Triage agent").
 		WithInstructions(TRIAGE_AGENT_INSTRUCTIONS).
 		WithAgentHandoffs(refine_agent, search_agent).
-		WithModel(openai.ChatModelGPT4oMini)
+		WithModel(model)
 }
@@ -1,8 +1,23 @@
 package shared
 
+import "api/internal/summary/source"
+
 type SearchResult struct {
 	Title string
 	URL   string
+	// Origin is the name of the search provider that found the result.
+	Origin string
+	// Source is how to summarize the result, when URL alone is not enough.
+	Source source.Ref
+}
+
+// Ref returns the source to summarize for the result.
+func (r SearchResult) Ref() source.Ref {
+	if r.Source.Location != "" {
+		return r.Source
+	}
+
+	return source.FromURL(r.URL)
 }
 
 type SearchParams struct {
@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// ErrMissingAPIKey is returned when an API key the request needs is not set.
+var ErrMissingAPIKey = errors.New("API key is not set")
+
+// NewYoutubeService creates a YouTube Data API client using the key in
+// YOUTUBE_API_KEY.
+func NewYoutubeService(ctx context.Context) (*youtube.Service, error) {
+	key := os.Getenv("YOUTUBE_API_KEY")
+
+	if key == "" {
+		return nil, fmt.Errorf("%w: YOUTUBE_API_KEY", ErrMissingAPIKey)
+	}
+
+	return youtube.NewService(ctx, option.WithAPIKey(key))
+}
@@ -0,0 +1,76 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnavailable is wrapped by errors for sources that cannot be fetched no
+// matter how often it is retried, such as private or removed videos.
+var ErrUnavailable = errors.New("source is unavailable")
+
+// ytDlpUnavailableMessages are parts of yt-dlp error messages for videos that
+// will not become available by retrying.
+var ytDlpUnavailableMessages = []string{
+	"private video",
+	"video unavailable",
+	"this video has been removed",
+	"this video is no longer available",
+	"account associated with this video has been terminated",
+	"members-only",
+	"join this channel to get access",
+	"sign in to confirm your age",
+	"this live event will begin",
+	"premieres in",
+	"is not a valid url",
+	"unsupported url",
+	"http error 404",
+	"http error 410",
+	"does not exist",
+}
+
+// YtDlpError describes a failed yt-dlp run, wrapping ErrUnavailable when its
+// stderr says the video or playlist cannot be downloaded at all. stderr may
+// be empty when err is an *exec.ExitError that captured it.
+func YtDlpError(msg string, err error, stderr string) error {
+	var exitErr *exec.ExitError
+
+	if stderr == "" && errors.As(err, &exitErr) {
+		stderr = string(exitErr.Stderr)
+	}
+
+	line := lastLine(stderr)
+	lower := strings.ToLower(stderr)
+
+	for _, m := range ytDlpUnavailableMessages {
+		if strings.Contains(lower, m) {
+			return fmt.Errorf("%w: %s: %s", ErrUnavailable, msg, line)
+		}
+	}
+
+	if line == "" {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	return fmt.Errorf("%s: %w: %s", msg, err, line)
+}
+
+// httpError describes a failed HTTP response, wrapping ErrUnavailable for
+// statuses that retrying will not change.
+func httpError(msg string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		return fmt.Errorf("%w: %s: %s", ErrUnavailable, msg, resp.Status)
+	default:
+		return fmt.Errorf("%s: %s", msg, resp.Status)
+	}
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+
+	return lines[len(lines)-1]
+}
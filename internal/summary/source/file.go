@@ -0,0 +1,34 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FileSource reads a local audio or video file, such as a recorded meeting.
+// The path must be readable by the worker.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Resolve(ctx context.Context) (Ref, string, error) {
+	if !fileExists(s.Path) {
+		return Ref{}, "", fmt.Errorf("%w: file %s does not exist", ErrUnavailable, s.Path)
+	}
+
+	title := strings.TrimSuffix(filepath.Base(s.Path), filepath.Ext(s.Path))
+
+	return FromFile(s.Path), title, nil
+}
+
+func (s FileSource) Fetch(ctx context.Context, dir string) (string, error) {
+	outputPath := audioPath(dir)
+
+	if err := toMP3(ctx, s.Path, outputPath); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
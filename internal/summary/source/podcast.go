@@ -0,0 +1,228 @@
+package source
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PodcastSource downloads the audio enclosure of an episode of an RSS feed.
+type PodcastSource struct {
+	FeedURL string
+	Episode string
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	Enclosure struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+// Resolve pins the episode to its GUID, falling back to the enclosure URL for
+// feeds without GUIDs.
+func (s PodcastSource) Resolve(ctx context.Context) (Ref, string, error) {
+	feed, item, err := s.episode(ctx)
+
+	if err != nil {
+		return Ref{}, "", err
+	}
+
+	episode := item.GUID
+
+	if episode == "" {
+		episode = item.Enclosure.URL
+	}
+
+	title := item.Title
+
+	if feed.Channel.Title != "" {
+		title = fmt.Sprintf("%s: %s", feed.Channel.Title, item.Title)
+	}
+
+	return FromPodcast(s.FeedURL, episode), title, nil
+}
+
+func (s PodcastSource) Fetch(ctx context.Context, dir string) (string, error) {
+	_, item, err := s.episode(ctx)
+
+	if err != nil {
+		return "", err
+	}
+
+	enclosurePath := filepath.Join(dir, "enclosure"+enclosureExt(item.Enclosure.URL))
+
+	if err := download(ctx, item.Enclosure.URL, enclosurePath); err != nil {
+		return "", err
+	}
+
+	outputPath := audioPath(dir)
+
+	if filepath.Ext(enclosurePath) == ".mp3" {
+		return outputPath, os.Rename(enclosurePath, outputPath)
+	}
+
+	if err := toMP3(ctx, enclosurePath, outputPath); err != nil {
+		return "", err
+	}
+
+	return outputPath, nil
+}
+
+// episode fetches the feed and finds the episode by GUID, enclosure URL,
+// 1-based position or part of its title, in that order.
+func (s PodcastSource) episode(ctx context.Context) (*rssFeed, *rssItem, error) {
+	feed, err := fetchFeed(ctx, s.FeedURL)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]rssItem, 0, len(feed.Channel.Items))
+
+	for _, item := range feed.Channel.Items {
+		if item.Enclosure.URL != "" {
+			items = append(items, item)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, nil, fmt.Errorf("%w: feed %s has no audio episodes", ErrUnavailable, s.FeedURL)
+	}
+
+	if s.Episode == "" {
+		return feed, &items[0], nil
+	}
+
+	for i, item := range items {
+		if item.GUID == s.Episode || item.Enclosure.URL == s.Episode {
+			return feed, &items[i], nil
+		}
+	}
+
+	if n, err := strconv.Atoi(s.Episode); err == nil && n >= 1 && n <= len(items) {
+		return feed, &items[n-1], nil
+	}
+
+	for i, item := range items {
+		if strings.Contains(strings.ToLower(item.Title), strings.ToLower(s.Episode)) {
+			return feed, &items[i], nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: episode %q not found in feed %s", ErrUnavailable, s.Episode, s.FeedURL)
+}
+
+func fetchFeed(ctx context.Context, feedURL string) (*rssFeed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed %s: %w", feedURL, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpError(fmt.Sprintf("failed to fetch feed %s", feedURL), resp)
+	}
+
+	var feed rssFeed
+
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to decode feed %s: %w", feedURL, err)
+	}
+
+	return &feed, nil
+}
+
+// download writes fileURL to filePath. A partial file left at filePath by an
+// earlier download is continued with a range request when the server
+// supports it.
+func download(ctx context.Context, fileURL string, filePath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+
+	if info, err := os.Stat(filePath); err == nil && info.Size() > 0 {
+		offset = info.Size()
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", fileURL, err)
+	}
+
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags = os.O_WRONLY | os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is already complete.
+		return nil
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+	default:
+		return httpError(fmt.Sprintf("failed to download %s", fileURL), resp)
+	}
+
+	f, err := os.OpenFile(filePath, flags, 0o644)
+
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader = resp.Body
+
+	if resp.ContentLength > 0 {
+		body = &progressReader{Reader: resp.Body, ctx: ctx, read: offset, total: offset + resp.ContentLength}
+	}
+
+	if _, err := io.Copy(f, body); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func enclosureExt(enclosureURL string) string {
+	u, err := url.Parse(enclosureURL)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(path.Ext(u.Path))
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package source
+
+import "os/exec"
+
+// killProcessGroup is a no-op where process groups are not supported, so
+// cancelling only kills cmd itself.
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,38 @@
+package source
+
+import (
+	"context"
+	"io"
+)
+
+// ProgressFunc receives how much of a Fetch is done, between 0 and 1.
+type ProgressFunc func(fraction float64)
+
+type progressKey struct{}
+
+// WithProgress returns a context that makes Fetch report its progress to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func reportProgress(ctx context.Context, fraction float64) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		fn(min(max(fraction, 0), 1))
+	}
+}
+
+// progressReader reports how much of a body of known size has been read.
+type progressReader struct {
+	io.Reader
+	ctx   context.Context
+	read  int64
+	total int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	reportProgress(r.ctx, float64(r.read)/float64(r.total))
+
+	return n, err
+}
@@ -0,0 +1,159 @@
+package source
+
+import (
+	"api/internal/summary/cache"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type Kind string
+
+const (
+	// KindURL is any page yt-dlp can extract audio from, YouTube included.
+	KindURL Kind = "url"
+	// KindPodcast is an episode of a podcast RSS feed.
+	KindPodcast Kind = "podcast"
+	// KindFile is an audio or video file on the worker's filesystem.
+	KindFile Kind = "file"
+)
+
+// Ref is a serializable reference to something that can be summarized.
+type Ref struct {
+	Kind Kind
+	// Location is the page URL, the feed URL or the file path.
+	Location string
+	// Episode selects a podcast episode by GUID, 1-based position in the feed
+	// or part of its title. An empty Episode means the latest one.
+	Episode string
+}
+
+// Item is a resolved Ref together with the title of what it points at.
+type Item struct {
+	Ref   Ref
+	Title string
+	// Language is the ISO 639-1 code of the spoken language, once it has
+	// been transcribed.
+	Language string
+}
+
+// Source fetches the audio a Ref points at.
+type Source interface {
+	// Resolve pins the reference to a concrete item, so it keeps pointing at
+	// the same audio later, and returns the item's title when it has one.
+	Resolve(ctx context.Context) (Ref, string, error)
+	// Fetch writes the audio as an mp3 file into dir and returns its path.
+	Fetch(ctx context.Context, dir string) (string, error)
+}
+
+func FromURL(URL string) Ref {
+	return Ref{Kind: KindURL, Location: strings.TrimSpace(URL)}
+}
+
+func FromFile(filePath string) Ref {
+	return Ref{Kind: KindFile, Location: filePath}
+}
+
+func FromPodcast(feedURL string, episode string) Ref {
+	return Ref{Kind: KindPodcast, Location: strings.TrimSpace(feedURL), Episode: strings.TrimSpace(episode)}
+}
+
+// Parse treats http(s) URLs as yt-dlp sources and anything else as a file
+// path, made absolute. Podcast feeds need FromPodcast, as their URLs look
+// like any other.
+func Parse(s string) (Ref, error) {
+	s = strings.TrimSpace(s)
+
+	if u, err := url.Parse(s); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return FromURL(s), nil
+	}
+
+	filePath, err := filepath.Abs(strings.TrimPrefix(s, "file://"))
+
+	if err != nil {
+		return Ref{}, err
+	}
+
+	return FromFile(filePath), nil
+}
+
+func New(ref Ref) (Source, error) {
+	switch ref.Kind {
+	case KindURL, "":
+		return URLSource{ref.Location}, nil
+	case KindPodcast:
+		return PodcastSource{ref.Location, ref.Episode}, nil
+	case KindFile:
+		return FileSource{ref.Location}, nil
+	default:
+		return nil, fmt.Errorf("unsupported source kind %q", ref.Kind)
+	}
+}
+
+// ID returns the stable cache key of the referenced item. URLs share
+// cache.VideoID, so a YouTube video has the same ID whatever form its link
+// takes.
+func (r Ref) ID() string {
+	switch r.Kind {
+	case KindPodcast:
+		return "podcast-" + shortHash(r.Location+"#"+r.Episode)
+	case KindFile:
+		return "file-" + shortHash(r.Location)
+	default:
+		return cache.VideoID(r.Location)
+	}
+}
+
+// Link returns a URL readers of the summary can follow, if there is one.
+func (r Ref) Link() string {
+	if r.Kind == KindFile {
+		return ""
+	}
+
+	return r.Location
+}
+
+func (r Ref) String() string {
+	if r.Kind == KindPodcast && r.Episode != "" {
+		return r.Location + "#" + r.Episode
+	}
+
+	return r.Location
+}
+
+func shortHash(s string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))[:16]
+}
+
+// toMP3 converts any audio or video file ffmpeg understands to an mp3 file,
+// dropping the video stream.
+func toMP3(ctx context.Context, inputPath string, outputPath string) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-y",
+		"-i", inputPath,
+		"-vn",
+		"-b:a", "128k",
+		outputPath,
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		return fmt.Errorf("ffmpeg failed to convert %s: %w: %s", filepath.Base(inputPath), err, lines[len(lines)-1])
+	}
+
+	return nil
+}
+
+func audioPath(dir string) string {
+	return filepath.Join(dir, "audio.mp3")
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
@@ -0,0 +1,113 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// URLSource downloads audio with yt-dlp, which supports YouTube and most
+// other video and audio sites.
+type URLSource struct {
+	URL string
+}
+
+func (s URLSource) Resolve(ctx context.Context) (Ref, string, error) {
+	return FromURL(s.URL), "", nil
+}
+
+// ytDlpProgressPrefix marks the progress lines printed by yt-dlp, as
+// "<prefix><downloaded bytes>/<total bytes>/<estimated total bytes>".
+const ytDlpProgressPrefix = "progress:"
+
+// Fetch downloads the audio into dir. yt-dlp continues from the partial
+// download of an earlier Fetch into the same dir. Cancelling ctx stops
+// yt-dlp and the processes it started.
+func (s URLSource) Fetch(ctx context.Context, dir string) (string, error) {
+	downloadPath := audioPath(dir)
+
+	cmd := exec.CommandContext(ctx, "yt-dlp",
+		"-f", "bestaudio",
+		"--extract-audio",
+		"--audio-format", "mp3",
+		"--audio-quality", "0", // best quality
+		"--continue",
+		"--newline",
+		"--progress-template", "download:"+ytDlpProgressPrefix+"%(progress.downloaded_bytes)s/%(progress.total_bytes)s/%(progress.total_bytes_estimate)s",
+		"-o", downloadPath,
+		// Ends the options, so a URL starting with "-" is not read as one.
+		"--",
+		s.URL,
+	)
+
+	killProcessGroup(cmd)
+	cmd.WaitDelay = 5 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start yt-dlp: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+
+	for scanner.Scan() {
+		if fraction, ok := parseYtDlpProgress(scanner.Text()); ok {
+			reportProgress(ctx, fraction)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+
+		return "", YtDlpError(fmt.Sprintf("yt-dlp failed to download %s", s.URL), err, stderr.String())
+	}
+
+	return downloadPath, nil
+}
+
+// parseYtDlpProgress parses a progress line printed with the
+// ytDlpProgressPrefix template. yt-dlp prints "NA" for unknown values.
+func parseYtDlpProgress(line string) (float64, bool) {
+	line, ok := strings.CutPrefix(strings.TrimSpace(line), ytDlpProgressPrefix)
+
+	if !ok {
+		return 0, false
+	}
+
+	fields := strings.Split(line, "/")
+
+	if len(fields) != 3 {
+		return 0, false
+	}
+
+	downloaded, err := strconv.ParseFloat(fields[0], 64)
+
+	if err != nil {
+		return 0, false
+	}
+
+	for _, field := range fields[1:] {
+		total, err := strconv.ParseFloat(field, 64)
+
+		if err == nil && total > 0 {
+			return downloaded / total, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key.
+var ErrNotFound = errors.New("object not found")
+
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Storage holds the artifacts produced by activities, addressed by
+// slash-separated object keys such as "cache/youtube-abc/audio.mp3".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+type Backend string
+
+const (
+	BackendLocal Backend = "local"
+	BackendS3    Backend = "s3"
+)
+
+type Config struct {
+	Backend Backend `yaml:"backend"`
+	// LocalDir is the root directory of the local backend.
+	LocalDir string   `yaml:"local_dir"`
+	S3       S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint"`
+	Bucket          string `yaml:"bucket"`
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	UseSSL          bool   `yaml:"use_ssl"`
+}
+
+func New(ctx context.Context, cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case BackendLocal:
+		return NewLocalStorage(cfg.LocalDir)
+	case BackendS3:
+		return NewS3Storage(ctx, cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+func ReadAll(ctx context.Context, s Storage, key string) ([]byte, error) {
+	r, err := s.Get(ctx, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// Download copies an object into a local file, for tools such as ffmpeg and
+// whisper that only work on files.
+func Download(ctx context.Context, s Storage, key string, filePath string) error {
+	r, err := s.Get(ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	f, err := os.Create(filePath)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func Upload(ctx context.Context, s Storage, key string, filePath string) error {
+	f, err := os.Open(filePath)
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return s.Put(ctx, key, f)
+}
@@ -0,0 +1,353 @@
+package style
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default is the style used when none is asked for.
+const Default = "standard"
+
+// fallback is the style whose sections and merge prompts styles without
+// their own use. It is pinned, so a new version of the default style does
+// not change the prompts of styles that were cached already.
+var fallback = Ref{Default, 1}
+
+//go:embed templates/*.yaml
+var builtin embed.FS
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ErrUnknownStyle is returned when a style or version is not in the library.
+var ErrUnknownStyle = errors.New("unknown summary style")
+
+// Ref pins a style to one version, so every activity of a workflow run and
+// the cache agree on the prompts used.
+type Ref struct {
+	Name    string
+	Version int
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s@%d", r.Name, r.Version)
+}
+
+// CacheName tells apart the cached summaries of each style version. The
+// first version of the default style has none, so summaries cached before
+// styles existed are still used.
+func (r Ref) CacheName() string {
+	if r.Name == Default && r.Version == 1 {
+		return ""
+	}
+
+	return fmt.Sprintf("%s-v%d", r.Name, r.Version)
+}
+
+// Style is one version of a named set of prompt templates.
+type Style struct {
+	Ref
+	Description string
+	// Builtin is false for styles loaded from the styles directory.
+	Builtin bool
+
+	summary  *template.Template
+	sections *template.Template
+	merge    *template.Template
+}
+
+// Data is what the templates are executed with.
+type Data struct {
+	// Text is the transcript, the section of it or the section notes to
+	// merge.
+	Text string
+	// Part and Parts number the section, for the sections template.
+	Part  int
+	Parts int
+	// Language is the English name of the spoken language, empty when it is
+	// unknown.
+	Language string
+	// TimestampInstructions asks the model to keep [hh:mm:ss] markers.
+	TimestampInstructions string
+	// LanguageInstructions asks the model to write in the spoken language.
+	LanguageInstructions string
+}
+
+// Summary renders the prompt that summarizes a whole transcript at once.
+func (s *Style) Summary(data Data) (string, error) {
+	return execute(s.summary, data)
+}
+
+// Sections renders the prompt that takes notes on one section of a long
+// transcript.
+func (s *Style) Sections(data Data) (string, error) {
+	return execute(s.sections, data)
+}
+
+// Merge renders the prompt that merges section notes into the summary.
+func (s *Style) Merge(data Data) (string, error) {
+	return execute(s.merge, data)
+}
+
+func execute(t *template.Template, data Data) (string, error) {
+	var b bytes.Buffer
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// file is the YAML layout of a template file. Sections and merge default to
+// the prompts of the fallback style.
+type file struct {
+	Name        string `yaml:"name"`
+	Version     int    `yaml:"version"`
+	Description string `yaml:"description"`
+	Summary     string `yaml:"summary"`
+	Sections    string `yaml:"sections"`
+	Merge       string `yaml:"merge"`
+
+	builtin bool
+}
+
+// Library holds every version of every style.
+type Library struct {
+	styles map[string][]*Style
+}
+
+// Load reads the built-in styles and then the *.yaml files of dir, when it
+// is not empty. Files in dir add styles or versions, and may not reuse the
+// name and version of a style loaded already, as summaries are cached by
+// them.
+func Load(dir string) (*Library, error) {
+	lib := &Library{styles: make(map[string][]*Style)}
+	files := make([]file, 0)
+
+	if err := readFiles(builtin, "templates", &files); err != nil {
+		return nil, err
+	}
+
+	for i := range files {
+		files[i].builtin = true
+	}
+
+	if dir != "" {
+		// fs.Glob ignores a missing directory, which is a typo worth
+		// reporting.
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("failed to read styles directory: %w", err)
+		}
+
+		if err := readFiles(os.DirFS(dir), ".", &files); err != nil {
+			return nil, err
+		}
+	}
+
+	// The fallback style goes first, as the others use its prompts.
+	slices.SortStableFunc(files, func(a, b file) int {
+		isFallback := func(f file) bool { return f.Name == fallback.Name && f.Version == fallback.Version }
+
+		switch {
+		case isFallback(a) && !isFallback(b):
+			return -1
+		case !isFallback(a) && isFallback(b):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, f := range files {
+		if err := lib.add(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := lib.Resolve(Default); err != nil {
+		return nil, fmt.Errorf("the %q style is missing", Default)
+	}
+
+	return lib, nil
+}
+
+func readFiles(fsys fs.FS, dir string, files *[]file) error {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range paths {
+		content, err := fs.ReadFile(fsys, name)
+
+		if err != nil {
+			return fmt.Errorf("failed to read style %s: %w", name, err)
+		}
+
+		var f file
+
+		if err := yaml.Unmarshal(content, &f); err != nil {
+			return fmt.Errorf("failed to parse style %s: %w", name, err)
+		}
+
+		if !namePattern.MatchString(f.Name) || f.Version < 1 || f.Summary == "" {
+			return fmt.Errorf("style %s needs a lowercase name, a version of at least 1 and a summary template", name)
+		}
+
+		*files = append(*files, f)
+	}
+
+	return nil
+}
+
+func (lib *Library) add(f file) error {
+	s := &Style{
+		Ref:         Ref{f.Name, f.Version},
+		Description: f.Description,
+		Builtin:     f.builtin,
+	}
+
+	parse := func(text string, fallback *template.Template) (*template.Template, error) {
+		if text == "" {
+			if fallback == nil {
+				return nil, fmt.Errorf("style %s needs sections and merge templates", s.Ref)
+			}
+
+			return fallback, nil
+		}
+
+		t, err := template.New(s.Ref.String()).Parse(text)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid template in style %s: %w", s.Ref, err)
+		}
+
+		// Rendering once catches fields Data does not have before any
+		// workflow uses the style.
+		if _, err := execute(t, Data{}); err != nil {
+			return nil, fmt.Errorf("invalid template in style %s: %w", s.Ref, err)
+		}
+
+		return t, nil
+	}
+
+	if existing, err := lib.Get(s.Ref); err == nil {
+		if existing.Builtin {
+			return fmt.Errorf("style %s is built in, give yours another version", s.Ref)
+		}
+
+		return fmt.Errorf("style %s is defined twice, give it another version", s.Ref)
+	}
+
+	var defaultSections, defaultMerge *template.Template
+
+	if d, err := lib.Get(fallback); err == nil {
+		defaultSections, defaultMerge = d.sections, d.merge
+	}
+
+	var err error
+
+	if s.summary, err = parse(f.Summary, nil); err != nil {
+		return err
+	}
+
+	if s.sections, err = parse(f.Sections, defaultSections); err != nil {
+		return err
+	}
+
+	if s.merge, err = parse(f.Merge, defaultMerge); err != nil {
+		return err
+	}
+
+	lib.styles[f.Name] = append(lib.styles[f.Name], s)
+
+	slices.SortFunc(lib.styles[f.Name], func(a, b *Style) int { return a.Version - b.Version })
+
+	return nil
+}
+
+func (lib *Library) latest(name string) int {
+	versions := lib.styles[name]
+
+	if len(versions) == 0 {
+		return 0
+	}
+
+	return versions[len(versions)-1].Version
+}
+
+// Resolve turns "name" or "name@version" into a Ref, picking the latest
+// version when none is given. An empty spec is the default style.
+func (lib *Library) Resolve(spec string) (Ref, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+
+	if spec == "" {
+		spec = Default
+	}
+
+	name, version, pinned := strings.Cut(spec, "@")
+	ref := Ref{Name: name, Version: lib.latest(name)}
+
+	if ref.Version == 0 {
+		return Ref{}, fmt.Errorf("%w %q", ErrUnknownStyle, name)
+	}
+
+	if pinned {
+		v, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+
+		if err != nil {
+			return Ref{}, fmt.Errorf("%w %q", ErrUnknownStyle, spec)
+		}
+
+		ref.Version = v
+	}
+
+	if _, err := lib.Get(ref); err != nil {
+		return Ref{}, err
+	}
+
+	return ref, nil
+}
+
+// Get returns the style version ref points at.
+func (lib *Library) Get(ref Ref) (*Style, error) {
+	for _, s := range lib.styles[ref.Name] {
+		if s.Version == ref.Version {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w %q", ErrUnknownStyle, ref.String())
+}
+
+// List returns every style version, sorted by name and version.
+func (lib *Library) List() []*Style {
+	styles := make([]*Style, 0)
+
+	for _, versions := range lib.styles {
+		styles = append(styles, versions...)
+	}
+
+	slices.SortFunc(styles, func(a, b *Style) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+
+		return a.Version - b.Version
+	})
+
+	return styles
+}
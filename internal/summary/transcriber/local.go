@@ -0,0 +1,223 @@
+package transcriber
+
+import (
+	"api/internal/summary/language"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultWhisperCppBinary    = "whisper-cli"
+	defaultFasterWhisperBinary = "whisper-ctranslate2"
+	defaultFasterWhisperModel  = "small"
+)
+
+// WhisperCppTranscriber runs a whisper.cpp build locally, so audio never
+// leaves the worker machine.
+type WhisperCppTranscriber struct {
+	binary string
+	model  string
+}
+
+func NewWhisperCppTranscriber(binary, model string) (*WhisperCppTranscriber, error) {
+	if model == "" {
+		return nil, errors.New("whisper.cpp transcriber requires a model file path")
+	}
+
+	if binary == "" {
+		binary = defaultWhisperCppBinary
+	}
+
+	return &WhisperCppTranscriber{
+		binary: binary,
+		model:  model,
+	}, nil
+}
+
+// whisperCppOutput is the file written by whisper.cpp with -oj. Offsets are
+// in milliseconds.
+type whisperCppOutput struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+func (t *WhisperCppTranscriber) Transcribe(ctx context.Context, filePath string) (Transcript, error) {
+	workDir, err := os.MkdirTemp("", "whisper-cpp-*")
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	defer os.RemoveAll(workDir)
+
+	// whisper.cpp only reliably accepts 16kHz mono PCM input.
+	wavPath := filepath.Join(workDir, "input.wav")
+
+	err = runCommand(ctx, "ffmpeg",
+		"-y",
+		"-i", filePath,
+		"-ar", "16000",
+		"-ac", "1",
+		"-c:a", "pcm_s16le",
+		wavPath,
+	)
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	outputPrefix := filepath.Join(workDir, "transcript")
+
+	err = runCommand(ctx, t.binary,
+		"-m", t.model,
+		"-f", wavPath,
+		"-l", "auto",
+		"-oj",
+		"-of", outputPrefix,
+		"-np",
+	)
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	var output whisperCppOutput
+
+	if err := readOutput(outputPrefix+".json", &output); err != nil {
+		return Transcript{}, err
+	}
+
+	segments := make([]Segment, 0, len(output.Transcription))
+
+	for _, s := range output.Transcription {
+		segments = append(segments, Segment{
+			Start: float64(s.Offsets.From) / 1000,
+			End:   float64(s.Offsets.To) / 1000,
+			Text:  strings.TrimSpace(s.Text),
+		})
+	}
+
+	transcript := NewTranscript(segments)
+	transcript.Language = language.Code(output.Result.Language)
+
+	return transcript, nil
+}
+
+// FasterWhisperTranscriber runs faster-whisper through the whisper-ctranslate2
+// command line tool.
+type FasterWhisperTranscriber struct {
+	binary string
+	model  string
+}
+
+func NewFasterWhisperTranscriber(binary, model string) *FasterWhisperTranscriber {
+	if binary == "" {
+		binary = defaultFasterWhisperBinary
+	}
+
+	if model == "" {
+		model = defaultFasterWhisperModel
+	}
+
+	return &FasterWhisperTranscriber{
+		binary: binary,
+		model:  model,
+	}
+}
+
+// fasterWhisperOutput is the file written by whisper-ctranslate2 with
+// --output_format json.
+type fasterWhisperOutput struct {
+	Language string `json:"language"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+func (t *FasterWhisperTranscriber) Transcribe(ctx context.Context, filePath string) (Transcript, error) {
+	workDir, err := os.MkdirTemp("", "faster-whisper-*")
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	defer os.RemoveAll(workDir)
+
+	err = runCommand(ctx, t.binary,
+		filePath,
+		"--model", t.model,
+		"--output_dir", workDir,
+		"--output_format", "json",
+	)
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+
+	var output fasterWhisperOutput
+
+	if err := readOutput(filepath.Join(workDir, baseName+".json"), &output); err != nil {
+		return Transcript{}, err
+	}
+
+	segments := make([]Segment, 0, len(output.Segments))
+
+	for _, s := range output.Segments {
+		segments = append(segments, Segment{
+			Start: s.Start,
+			End:   s.End,
+			Text:  strings.TrimSpace(s.Text),
+		})
+	}
+
+	transcript := NewTranscript(segments)
+	transcript.Language = language.Code(output.Language)
+
+	return transcript, nil
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+func readOutput(path string, v any) error {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode transcript: %w", err)
+	}
+
+	return nil
+}
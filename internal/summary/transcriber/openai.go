@@ -0,0 +1,84 @@
+package transcriber
+
+import (
+	"api/internal/summary/language"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/openai/openai-go"
+)
+
+type OpenAITranscriber struct {
+	openAIClient openai.Client
+	model        string
+}
+
+// NewOpenAITranscriber uses the whisper-1 model unless another one is given.
+func NewOpenAITranscriber(openAIClient openai.Client, model string) *OpenAITranscriber {
+	if model == "" {
+		model = openai.AudioModelWhisper1
+	}
+
+	return &OpenAITranscriber{
+		openAIClient,
+		model,
+	}
+}
+
+// verboseTranscription is the subset of the "verbose_json" response format
+// that the typed client does not expose.
+type verboseTranscription struct {
+	Text string `json:"text"`
+	// Language is the English name of the detected language.
+	Language string `json:"language"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, filePath string) (Transcript, error) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	defer file.Close()
+
+	transcription, err := t.openAIClient.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
+		Model:                  t.model,
+		File:                   file,
+		ResponseFormat:         openai.AudioResponseFormatVerboseJSON,
+		TimestampGranularities: []string{"segment"},
+	})
+
+	if err != nil {
+		return Transcript{}, err
+	}
+
+	var verbose verboseTranscription
+
+	if err := json.Unmarshal([]byte(transcription.RawJSON()), &verbose); err != nil {
+		return Transcript{}, fmt.Errorf("failed to decode transcription segments: %w", err)
+	}
+
+	segments := make([]Segment, 0, len(verbose.Segments))
+
+	for _, s := range verbose.Segments {
+		segments = append(segments, Segment{
+			Start: s.Start,
+			End:   s.End,
+			Text:  s.Text,
+		})
+	}
+
+	return Transcript{
+		Text:     verbose.Text,
+		Segments: segments,
+		Language: language.Code(verbose.Language),
+	}, nil
+}
@@ -0,0 +1,45 @@
+package transcriber
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// Transcriber turns an audio file on the worker's disk into a timestamped
+// transcript.
+type Transcriber interface {
+	Transcribe(ctx context.Context, filePath string) (Transcript, error)
+}
+
+type Backend string
+
+const (
+	BackendOpenAI        Backend = "openai"
+	BackendWhisperCpp    Backend = "whisper-cpp"
+	BackendFasterWhisper Backend = "faster-whisper"
+)
+
+type Config struct {
+	Backend Backend `yaml:"backend"`
+	// Binary is the executable used by the local backends. Defaults to
+	// "whisper-cli" for whisper.cpp and "whisper-ctranslate2" for faster-whisper.
+	Binary string `yaml:"binary"`
+	// Model is a model name (OpenAI, faster-whisper) or model file path
+	// (whisper.cpp).
+	Model string `yaml:"model"`
+}
+
+func New(cfg Config, openAIClient openai.Client) (Transcriber, error) {
+	switch cfg.Backend {
+	case BackendOpenAI:
+		return NewOpenAITranscriber(openAIClient, cfg.Model), nil
+	case BackendWhisperCpp:
+		return NewWhisperCppTranscriber(cfg.Binary, cfg.Model)
+	case BackendFasterWhisper:
+		return NewFasterWhisperTranscriber(cfg.Binary, cfg.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown transcriber backend %q", cfg.Backend)
+	}
+}
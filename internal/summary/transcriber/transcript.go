@@ -0,0 +1,173 @@
+package transcriber
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Segment is a span of transcribed speech. Start and End are offsets in
+// seconds from the beginning of the transcribed audio.
+type Segment struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+type Transcript struct {
+	Text     string
+	Segments []Segment
+	// Language is the ISO 639-1 code of the spoken language detected by the
+	// transcriber, empty when it is unknown.
+	Language string
+}
+
+func NewTranscript(segments []Segment) Transcript {
+	return Transcript{
+		Text:     JoinSegments(segments),
+		Segments: segments,
+	}
+}
+
+func JoinSegments(segments []Segment) string {
+	parts := make([]string, 0, len(segments))
+
+	for _, s := range segments {
+		text := strings.TrimSpace(s.Text)
+
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// EstimateTokens approximates the model token count of text using the
+// common rule of thumb of four characters per token.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// GroupByTokens splits texts into consecutive groups of at most maxTokens
+// estimated tokens each, except for single texts that are longer on their
+// own. Every group holds at least minSize texts when there are that many, a
+// short last group being added to the one before it.
+func GroupByTokens(texts []string, maxTokens int, minSize int) [][]string {
+	groups := make([][]string, 0)
+	current := make([]string, 0)
+	currentTokens := 0
+
+	for _, t := range texts {
+		tokens := EstimateTokens(t)
+
+		if currentTokens+tokens > maxTokens && len(current) >= max(minSize, 1) {
+			groups = append(groups, current)
+			current = make([]string, 0)
+			currentTokens = 0
+		}
+
+		current = append(current, t)
+		currentTokens += tokens
+	}
+
+	switch {
+	case len(current) == 0:
+	case len(current) < minSize && len(groups) > 0:
+		groups[len(groups)-1] = append(groups[len(groups)-1], current...)
+	default:
+		groups = append(groups, current)
+	}
+
+	return groups
+}
+
+// Sections splits the transcript into consecutive parts of at most maxTokens
+// estimated tokens each, cutting only between segments.
+func (t Transcript) Sections(maxTokens int) []Transcript {
+	segments := t.Segments
+
+	if len(segments) == 0 {
+		segments = segmentsFromWords(t.Text)
+	}
+
+	sections := make([]Transcript, 0)
+	current := make([]Segment, 0)
+	currentTokens := 0
+
+	for _, s := range segments {
+		tokens := EstimateTokens(s.Text) + 1
+
+		if currentTokens+tokens > maxTokens && len(current) > 0 {
+			sections = append(sections, NewTranscript(current))
+			current = make([]Segment, 0)
+			currentTokens = 0
+		}
+
+		current = append(current, s)
+		currentTokens += tokens
+	}
+
+	if len(current) > 0 {
+		sections = append(sections, NewTranscript(current))
+	}
+
+	return sections
+}
+
+// segmentsFromWords is used for transcripts without timing information, so
+// that they can still be split. The returned segments carry no timestamps.
+func segmentsFromWords(text string) []Segment {
+	words := strings.Fields(text)
+	segments := make([]Segment, 0, len(words))
+
+	for _, w := range words {
+		segments = append(segments, Segment{Text: w})
+	}
+
+	return segments
+}
+
+// timestampedLineSeconds is roughly how much speech goes on one line of
+// TimestampedText, so markers stay useful without bloating the prompt.
+const timestampedLineSeconds = 30
+
+// TimestampedText renders the transcript as lines prefixed with the
+// [hh:mm:ss] offset at which each line starts. Transcripts without segments
+// are returned as plain text.
+func (t Transcript) TimestampedText() string {
+	if len(t.Segments) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+
+	lineStart := -1.0
+	line := make([]Segment, 0)
+
+	flush := func() {
+		if len(line) == 0 {
+			return
+		}
+
+		fmt.Fprintf(&b, "[%s] %s\n", FormatTimestamp(lineStart), JoinSegments(line))
+		line = line[:0]
+	}
+
+	for _, s := range t.Segments {
+		if lineStart < 0 || s.Start-lineStart >= timestampedLineSeconds {
+			flush()
+			lineStart = s.Start
+		}
+
+		line = append(line, s)
+	}
+
+	flush()
+
+	return b.String()
+}
+
+func FormatTimestamp(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total%3600/60, total%60)
+}
@@ -0,0 +1,54 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// ErrNotRunning is returned when canceling a workflow that already closed.
+var ErrNotRunning = errors.New("workflow is not running")
+
+// CancelWorkflow requests the cancellation of a running workflow and returns
+// the IDs of the workflows it canceled. Canceling a session also cancels the
+// summary started for its selection, which runs after the session completed.
+// Children of a playlist are canceled by the playlist itself.
+func CancelWorkflow(ctx context.Context, temporalClient client.Client, workflowID string) ([]string, error) {
+	resp, err := temporalClient.DescribeWorkflowExecution(ctx, workflowID, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	canceled := make([]string, 0, 2)
+
+	if info.GetStatus() == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		if err := temporalClient.CancelWorkflow(ctx, workflowID, ""); err != nil {
+			return nil, err
+		}
+
+		canceled = append(canceled, workflowID)
+	}
+
+	if info.GetType().GetName() == "InteractiveWorkflow" {
+		summaryID := SessionSummaryWorkflowID(workflowID)
+		summary, err := temporalClient.DescribeWorkflowExecution(ctx, summaryID, "")
+
+		if err == nil && summary.GetWorkflowExecutionInfo().GetStatus() == enums.WORKFLOW_EXECUTION_STATUS_RUNNING {
+			if err := temporalClient.CancelWorkflow(ctx, summaryID, ""); err != nil {
+				return canceled, err
+			}
+
+			canceled = append(canceled, summaryID)
+		}
+	}
+
+	if len(canceled) == 0 {
+		return nil, ErrNotRunning
+	}
+
+	return canceled, nil
+}
@@ -0,0 +1,306 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/source"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	StatusAwaitsQuestion Status = "awaits_question"
+	StatusAnswering      Status = "answering"
+)
+
+const UpdateAskQuestion = "ask_question"
+
+// defaultChatIdleTimeout is how long a chat waits for the next question
+// before it completes.
+const defaultChatIdleTimeout = 24 * time.Hour
+
+type VideoChatParams struct {
+	Source source.Ref
+	// IdleTimeout completes the chat after this long without a question,
+	// defaultChatIdleTimeout if zero.
+	IdleTimeout time.Duration
+	// History carries the conversation over continue-as-new.
+	History []activity.ChatTurn
+}
+
+type VideoChatState struct {
+	Status  Status
+	VideoID string
+	Title   string
+	URL     string
+	History []activity.ChatTurn
+	// Error says why the chat failed when Status is StatusError.
+	Error string
+}
+
+// VideoChatWorkflow answers questions about a video from its cached
+// transcript, so the video must have been transcribed before. Questions come
+// in through the UpdateAskQuestion update, are answered one at a time and
+// kept in the workflow state, which continues as new when its history grows
+// too long.
+func VideoChatWorkflow(ctx workflow.Context, params VideoChatParams) (err error) {
+	state := VideoChatState{
+		Status:  StatusPending,
+		History: params.History,
+	}
+
+	if state.History == nil {
+		state.History = []activity.ChatTurn{}
+	}
+
+	err = workflow.SetQueryHandler(ctx, QueryCheckState, func() (VideoChatState, error) {
+		return state, nil
+	})
+
+	if err != nil {
+		return
+	}
+
+	err = workflow.SetUpdateHandlerWithOptions(
+		ctx,
+		UpdateAskQuestion,
+		func(ctx workflow.Context, question string) (activity.ChatTurn, error) {
+			var turn activity.ChatTurn
+
+			// Questions asked while the chat loads or answers another one
+			// wait for their turn.
+			err := workflow.Await(ctx, func() bool {
+				return state.Status != StatusPending && state.Status != StatusAnswering
+			})
+
+			if err != nil {
+				return turn, err
+			}
+
+			if state.Status != StatusAwaitsQuestion {
+				return turn, invalidUpdateError("the chat has ended with status %q", state.Status)
+			}
+
+			state.Status = StatusAnswering
+
+			defer func() {
+				if state.Status == StatusAnswering {
+					state.Status = StatusAwaitsQuestion
+				}
+			}()
+
+			history := state.History
+
+			if len(history) > activity.ChatHistoryTurns {
+				history = history[len(history)-activity.ChatHistoryTurns:]
+			}
+
+			var answer activity.ChatAnswer
+
+			err = workflow.ExecuteActivity(
+				modelOptions(ctx),
+				(*activity.AudioProcessActivities).AnswerQuestion,
+				activity.ChatQuestion{
+					VideoID:  state.VideoID,
+					Title:    state.Title,
+					URL:      state.URL,
+					History:  history,
+					Question: question,
+				},
+			).Get(ctx, &answer)
+
+			if err != nil {
+				return turn, err
+			}
+
+			turn = activity.ChatTurn{
+				Question:  question,
+				Answer:    answer.Answer,
+				Citations: answer.Citations,
+				AskedAt:   workflow.Now(ctx),
+			}
+
+			state.History = append(state.History, turn)
+
+			return turn, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(ctx workflow.Context, question string) error {
+				if strings.TrimSpace(question) == "" {
+					return invalidUpdateError("question is required")
+				}
+
+				switch state.Status {
+				case StatusPending, StatusAwaitsQuestion, StatusAnswering:
+					return nil
+				default:
+					return invalidUpdateError("cannot accept a question while in status %q", state.Status)
+				}
+			},
+		},
+	)
+
+	if err != nil {
+		return
+	}
+
+	// fail records why the chat ended, so it can still be queried.
+	fail := func(err error) error {
+		if temporal.IsCanceledError(err) {
+			state.Status = StatusCanceled
+			return err
+		}
+
+		state.Status = StatusError
+		state.Error = FailureReason(err)
+
+		return err
+	}
+
+	var resolved source.Item
+
+	err = workflow.ExecuteActivity(lookupOptions(ctx), activity.ResolveSource, params.Source).Get(ctx, &resolved)
+
+	if err != nil {
+		return fail(err)
+	}
+
+	state.VideoID = resolved.Ref.ID()
+	state.Title = resolved.Title
+	state.URL = resolved.Ref.Link()
+
+	var cached activity.CachedArtifacts
+
+	err = workflow.ExecuteActivity(storageOptions(ctx), (*activity.ArtifactActivities).LookupCache, state.VideoID, "").Get(ctx, &cached)
+
+	if err != nil {
+		return fail(err)
+	}
+
+	if cached.TranscriptKey == "" {
+		return fail(temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s has no cached transcript, summarize it first", params.Source),
+			activity.ErrTypeSourceUnavailable,
+			nil,
+		))
+	}
+
+	state.Status = StatusAwaitsQuestion
+
+	idleTimeout := params.IdleTimeout
+
+	if idleTimeout <= 0 {
+		idleTimeout = defaultChatIdleTimeout
+	}
+
+	for {
+		turns := len(state.History)
+
+		asked, err := workflow.AwaitWithTimeout(ctx, idleTimeout, func() bool {
+			return state.Status == StatusAnswering || len(state.History) != turns
+		})
+
+		if err != nil {
+			return fail(err)
+		}
+
+		if !asked {
+			break
+		}
+
+		err = workflow.Await(ctx, func() bool { return state.Status != StatusAnswering })
+
+		if err != nil {
+			return fail(err)
+		}
+
+		if workflow.GetInfo(ctx).GetContinueAsNewSuggested() {
+			err = workflow.Await(ctx, func() bool {
+				return state.Status != StatusAnswering && workflow.AllHandlersFinished(ctx)
+			})
+
+			if err != nil {
+				return fail(err)
+			}
+
+			params.History = state.History
+
+			return workflow.NewContinueAsNewError(ctx, VideoChatWorkflow, params)
+		}
+	}
+
+	state.Status = StatusCompleted
+
+	// Questions that arrived together with the timeout are rejected before
+	// the chat completes.
+	return workflow.Await(ctx, func() bool { return workflow.AllHandlersFinished(ctx) })
+}
+
+// NewChatID returns the workflow ID of a new VideoChatWorkflow.
+func NewChatID() string {
+	return fmt.Sprintf("chat-workflow-%s", uuid.New().String())
+}
+
+func StartVideoChatWorkflow(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	params VideoChatParams,
+) (client.WorkflowRun, error) {
+	return temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        workflowID,
+			TaskQueue: settings.TaskQueue,
+		},
+		VideoChatWorkflow,
+		params,
+	)
+}
+
+// AskQuestion asks a running chat a question and waits for the answer.
+func AskQuestion(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	question string,
+) (activity.ChatTurn, error) {
+	var turn activity.ChatTurn
+
+	handle, err := temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID:   workflowID,
+		UpdateName:   UpdateAskQuestion,
+		Args:         []any{question},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+
+	if err != nil {
+		return turn, err
+	}
+
+	err = handle.Get(ctx, &turn)
+
+	return turn, err
+}
+
+// QueryChatState returns the current state of a chat, including its whole
+// conversation.
+func QueryChatState(ctx context.Context, temporalClient client.Client, workflowID string) (VideoChatState, error) {
+	var state VideoChatState
+
+	result, err := temporalClient.QueryWorkflow(ctx, workflowID, "", QueryCheckState)
+
+	if err != nil {
+		return state, err
+	}
+
+	err = result.Get(&state)
+
+	return state, err
+}
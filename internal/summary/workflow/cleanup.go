@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// cleanupScheduleID is the schedule that runs CleanupWorkflow.
+const cleanupScheduleID = "artifact-cleanup"
+
+// CleanupWorkflow deletes the artifacts that outlived the retention policy
+// and reports how much space it reclaimed.
+func CleanupWorkflow(ctx workflow.Context, policy activity.RetentionPolicy) (report activity.CleanupReport, err error) {
+	err = workflow.ExecuteActivity(
+		cleanupOptions(ctx),
+		(*activity.ArtifactActivities).RemoveExpiredArtifacts,
+		policy,
+	).Get(ctx, &report)
+
+	if err != nil {
+		return report, err
+	}
+
+	workflow.GetLogger(ctx).Info(
+		"Removed expired artifacts",
+		"Objects", report.Objects,
+		"ReclaimedBytes", report.ReclaimedBytes,
+	)
+
+	return report, nil
+}
+
+// ScheduleCleanup makes the cleanup schedule run CleanupWorkflow with policy
+// every interval, creating the schedule or updating the existing one. An
+// interval of zero deletes the schedule.
+func ScheduleCleanup(
+	ctx context.Context,
+	temporalClient client.Client,
+	policy activity.RetentionPolicy,
+	interval time.Duration,
+) error {
+	handle := temporalClient.ScheduleClient().GetHandle(ctx, cleanupScheduleID)
+
+	if interval <= 0 {
+		err := handle.Delete(ctx)
+
+		var notFound *serviceerror.NotFound
+
+		if errors.As(err, &notFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	spec := client.ScheduleSpec{
+		Intervals: []client.ScheduleIntervalSpec{{Every: interval}},
+	}
+
+	action := &client.ScheduleWorkflowAction{
+		ID:        "artifact-cleanup-run",
+		Workflow:  CleanupWorkflow,
+		Args:      []interface{}{policy},
+		TaskQueue: settings.TaskQueue,
+	}
+
+	_, err := temporalClient.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID:      cleanupScheduleID,
+		Spec:    spec,
+		Action:  action,
+		Overlap: enums.SCHEDULE_OVERLAP_POLICY_SKIP,
+	})
+
+	if !errors.Is(err, temporal.ErrScheduleAlreadyRunning) {
+		return err
+	}
+
+	return handle.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			schedule := input.Description.Schedule
+			schedule.Spec = &spec
+			schedule.Action = action
+
+			return &client.ScheduleUpdate{Schedule: &schedule}, nil
+		},
+	})
+}
+
+// RunCleanup runs CleanupWorkflow right away and waits for its report.
+func RunCleanup(
+	ctx context.Context,
+	temporalClient client.Client,
+	policy activity.RetentionPolicy,
+) (activity.CleanupReport, error) {
+	var report activity.CleanupReport
+
+	run, err := temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        fmt.Sprintf("artifact-cleanup-%s", time.Now().Format("20060102150405")),
+			TaskQueue: settings.TaskQueue,
+		},
+		CleanupWorkflow,
+		policy,
+	)
+
+	if err != nil {
+		return report, err
+	}
+
+	err = run.Get(ctx, &report)
+
+	return report, err
+}
@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"errors"
+	"fmt"
+
+	"go.temporal.io/sdk/temporal"
+)
+
+// failureReasons explain the application error types of the activities.
+var failureReasons = map[string]string{
+	activity.ErrTypeSourceUnavailable:  "the video or episode is private, removed or does not exist",
+	activity.ErrTypeInvalidCredentials: "an API key is missing or was rejected",
+	activity.ErrTypeQuotaExceeded:      "the OpenAI or YouTube quota is exhausted",
+	activity.ErrTypeContentPolicy:      "the model refused to process the content",
+	activity.ErrTypeInvalidRequest:     "an API rejected the request, check the configured models",
+	activity.ErrTypeRateLimited:        "the API kept rate limiting the requests",
+}
+
+// FailureReason explains why a workflow or activity failed, in words fit for
+// the user, followed by the underlying error message.
+func FailureReason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var appErr *temporal.ApplicationError
+
+	if errors.As(err, &appErr) {
+		if reason, ok := failureReasons[appErr.Type()]; ok {
+			return fmt.Sprintf("%s (%s)", reason, appErr.Message())
+		}
+
+		return appErr.Message()
+	}
+
+	var timeoutErr *temporal.TimeoutError
+
+	if errors.As(err, &timeoutErr) {
+		return fmt.Sprintf("a step timed out (%s)", timeoutErr.TimeoutType())
+	}
+
+	var canceledErr *temporal.CanceledError
+
+	if errors.As(err, &canceledErr) {
+		return "it was canceled"
+	}
+
+	// Temporal wraps activity and child workflow failures in errors that
+	// describe where they happened, the innermost error says what happened.
+	for {
+		next := errors.Unwrap(err)
+
+		if next == nil {
+			return err.Error()
+		}
+
+		err = next
+	}
+}
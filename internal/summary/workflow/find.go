@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+// FindWorkflow returns the limit passages of transcripts and summaries that
+// are closest in meaning to query, across every indexed video.
+func FindWorkflow(ctx workflow.Context, query string, limit int) (passages []activity.Passage, err error) {
+	ctx = workflow.WithTaskQueue(modelOptions(ctx), settings.IndexTaskQueue)
+	ctx = workflow.WithScheduleToStartTimeout(ctx, indexScheduleToStartTimeout)
+
+	err = workflow.ExecuteActivity(ctx, (*activity.IndexActivities).FindPassages, query, limit).Get(ctx, &passages)
+
+	return passages, err
+}
+
+// IndexVideoWorkflow adds the transcript and summary of a video to the
+// semantic index. SummarizeWorkflow starts it without waiting, so summaries
+// are not held up by a busy or missing index worker.
+func IndexVideoWorkflow(ctx workflow.Context, videoID string, styleName string) (passages int, err error) {
+	err = workflow.ExecuteActivity(indexOptions(ctx), (*activity.IndexActivities).IndexVideo, videoID, styleName).Get(ctx, &passages)
+
+	return passages, err
+}
+
+// IndexLibraryWorkflow adds every video processed so far to the semantic
+// index, such as those summarized before it existed or while it was
+// unreachable.
+func IndexLibraryWorkflow(ctx workflow.Context) (report activity.IndexReport, err error) {
+	err = workflow.ExecuteActivity(indexOptions(ctx), (*activity.IndexActivities).IndexLibrary).Get(ctx, &report)
+
+	if err != nil {
+		return report, err
+	}
+
+	workflow.GetLogger(ctx).Info("Indexed the library", "Videos", report.Videos, "Passages", report.Passages)
+
+	return report, nil
+}
+
+func ExecuteFindWorkflow(
+	ctx context.Context,
+	temporalClient client.Client,
+	query string,
+	limit int,
+) ([]activity.Passage, error) {
+	run, err := temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        fmt.Sprintf("find-workflow-%s", uuid.New().String()),
+			TaskQueue: settings.TaskQueue,
+		},
+		FindWorkflow,
+		query,
+		limit,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var passages []activity.Passage
+
+	err = run.Get(ctx, &passages)
+
+	return passages, err
+}
+
+// RunIndexLibrary runs IndexLibraryWorkflow and waits for its report.
+func RunIndexLibrary(ctx context.Context, temporalClient client.Client) (activity.IndexReport, error) {
+	var report activity.IndexReport
+
+	run, err := temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        fmt.Sprintf("index-library-%s", time.Now().Format("20060102150405")),
+			TaskQueue: settings.TaskQueue,
+		},
+		IndexLibraryWorkflow,
+	)
+
+	if err != nil {
+		return report, err
+	}
+
+	err = run.Get(ctx, &report)
+
+	return report, err
+}
@@ -3,9 +3,13 @@ package workflow
 import (
 	"api/internal/summary/activity"
 	"api/internal/summary/shared"
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -21,15 +25,27 @@ const (
 	StatusRefined          Status = "refined"
 	StatusAwaitsSelection  Status = "awaits_selection"
 	StatusError            Status = "error"
+	StatusCanceled         Status = "canceled"
 	StatusCompleted        Status = "completed"
 )
 
 const (
-	QueryCheckState      = "state"
-	QueryAnswer          = "answer"
-	QuerySearchSelection = "search_selection"
+	QueryCheckState = "state"
 )
 
+const (
+	UpdateAnswer          = "answer"
+	UpdateSearchSelection = "search_selection"
+)
+
+// awaitStateChange versions waiting for updates to change the state of a
+// session, rather than checking it every few seconds.
+const awaitStateChange = "await-state-change"
+
+// ErrTypeInvalidUpdate is the application error type returned by update
+// validators when an update is rejected.
+const ErrTypeInvalidUpdate = "InvalidUpdate"
+
 type InteractiveWorkflowState struct {
 	Status              Status
 	InitQuery           string
@@ -37,6 +53,8 @@ type InteractiveWorkflowState struct {
 	RefinementAnswers   []string
 	SearchResults       []shared.SearchResult
 	SearchSelection     *int64
+	// Error says why the session failed when Status is StatusError.
+	Error string
 }
 
 func InteractiveWorkflow(ctx workflow.Context, params InteractiveWorkflowParams) (err error) {
@@ -59,32 +77,83 @@ func InteractiveWorkflow(ctx workflow.Context, params InteractiveWorkflowParams)
 		return
 	}
 
-	err = workflow.SetQueryHandler(ctx, QueryAnswer, func(answers []string) (bool, error) {
-		state.RefinementAnswers = answers
-		state.Status = StatusRefined
-		return true, nil
-	})
+	err = workflow.SetUpdateHandlerWithOptions(
+		ctx,
+		UpdateAnswer,
+		func(ctx workflow.Context, answers []string) error {
+			state.RefinementAnswers = answers
+			state.Status = StatusRefined
+			return nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(ctx workflow.Context, answers []string) error {
+				if state.Status != StatusAwaitsRefinement {
+					return invalidUpdateError("cannot accept answers while in status %q", state.Status)
+				}
+
+				if len(answers) != len(state.RefinementQuestions) {
+					return invalidUpdateError(
+						"expected %d answers, got %d",
+						len(state.RefinementQuestions),
+						len(answers),
+					)
+				}
+
+				return nil
+			},
+		},
+	)
 
 	if err != nil {
 		return
 	}
 
-	err = workflow.SetQueryHandler(ctx, QuerySearchSelection, func(choiceIndex int64) (bool, error) {
-		state.SearchSelection = &choiceIndex
-		state.Status = StatusCompleted
-
-		return true, nil
-	})
+	err = workflow.SetUpdateHandlerWithOptions(
+		ctx,
+		UpdateSearchSelection,
+		func(ctx workflow.Context, choiceIndex int64) (shared.SearchResult, error) {
+			state.SearchSelection = &choiceIndex
+			state.Status = StatusCompleted
+
+			return state.SearchResults[choiceIndex-1], nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(ctx workflow.Context, choiceIndex int64) error {
+				if state.Status != StatusAwaitsSelection {
+					return invalidUpdateError("cannot accept a selection while in status %q", state.Status)
+				}
+
+				if choiceIndex < 1 || choiceIndex > int64(len(state.SearchResults)) {
+					return invalidUpdateError(
+						"selection must be between 1 and %d, got %d",
+						len(state.SearchResults),
+						choiceIndex,
+					)
+				}
+
+				return nil
+			},
+		},
+	)
 
 	if err != nil {
 		return
 	}
 
-	ao := workflow.ActivityOptions{
-		StartToCloseTimeout: time.Minute * 5,
+	// fail records why the session ended, so it can still be queried.
+	fail := func(err error) error {
+		if temporal.IsCanceledError(err) {
+			state.Status = StatusCanceled
+			return err
+		}
+
+		state.Status = StatusError
+		state.Error = FailureReason(err)
+
+		return err
 	}
 
-	ctx = workflow.WithActivityOptions(ctx, ao)
+	ctx = modelOptions(ctx)
 
 	for state.Status != StatusCompleted {
 
@@ -100,11 +169,10 @@ func InteractiveWorkflow(ctx workflow.Context, params InteractiveWorkflowParams)
 		if state.Status == StatusPending {
 			var output shared.WithRefineOutput
 
-			err := workflow.ExecuteActivity(ctx, activity.Refine, state.InitQuery).Get(ctx, &output)
+			err = workflow.ExecuteActivity(ctx, (*activity.SearchActivities).Refine, state.InitQuery).Get(ctx, &output)
 
 			if err != nil {
-				state.Status = "error"
-				break
+				return fail(err)
 			}
 
 			if len(output.RefineQuestions) > 0 {
@@ -127,10 +195,10 @@ func InteractiveWorkflow(ctx workflow.Context, params InteractiveWorkflowParams)
 				enriched_query += fmt.Sprintf("- %s? %s \n", state.RefinementQuestions[i], answer)
 			}
 
-			err := workflow.ExecuteActivity(ctx, activity.Search, enriched_query).Get(ctx, &output)
+			err = workflow.ExecuteActivity(ctx, (*activity.SearchActivities).Search, enriched_query).Get(ctx, &output)
 
 			if err != nil {
-				state.Status = StatusError
+				return fail(err)
 			}
 
 			state.Status = StatusAwaitsSelection
@@ -143,8 +211,100 @@ func InteractiveWorkflow(ctx workflow.Context, params InteractiveWorkflowParams)
 			continue
 		}
 
-		workflow.Sleep(ctx, time.Second*3)
+		// Runs started before the change polled the state, and still do on
+		// replay.
+		if workflow.GetVersion(ctx, awaitStateChange, workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+			workflow.Sleep(ctx, time.Second*3)
+			continue
+		}
+
+		status := state.Status
+
+		err = workflow.Await(ctx, func() bool {
+			return state.Status != status
+		})
+
+		if err != nil {
+			return fail(err)
+		}
 	}
 
 	return
 }
+
+func StartInteractiveWorkflow(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	params InteractiveWorkflowParams,
+) (client.WorkflowRun, error) {
+	return temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        workflowID,
+			TaskQueue: settings.TaskQueue,
+		},
+		InteractiveWorkflow,
+		params,
+	)
+}
+
+func invalidUpdateError(format string, args ...any) error {
+	return temporal.NewApplicationError(fmt.Sprintf(format, args...), ErrTypeInvalidUpdate)
+}
+
+func SubmitAnswers(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	runID string,
+	answers []string,
+) error {
+	handle, err := temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID:   workflowID,
+		RunID:        runID,
+		UpdateName:   UpdateAnswer,
+		Args:         []any{answers},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return handle.Get(ctx, nil)
+}
+
+func SubmitSearchSelection(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	runID string,
+	selection int64,
+) (shared.SearchResult, error) {
+	var selected shared.SearchResult
+
+	handle, err := temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID:   workflowID,
+		RunID:        runID,
+		UpdateName:   UpdateSearchSelection,
+		Args:         []any{selection},
+		WaitForStage: client.WorkflowUpdateStageCompleted,
+	})
+
+	if err != nil {
+		return selected, err
+	}
+
+	err = handle.Get(ctx, &selected)
+
+	return selected, err
+}
+
+// IsInvalidUpdate reports whether err is a rejection from one of the
+// InteractiveWorkflow or VideoChatWorkflow update validators.
+func IsInvalidUpdate(err error) bool {
+	var appErr *temporal.ApplicationError
+
+	return errors.As(err, &appErr) && appErr.Type() == ErrTypeInvalidUpdate
+}
@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// heartbeatTimeout is how long activities that heartbeat their progress may
+// go silent before they are considered stuck and retried.
+const heartbeatTimeout = time.Minute
+
+// indexScheduleToStartTimeout is how long index activities wait for the
+// worker that keeps the index, so summaries don't wait on a deployment
+// without one.
+const indexScheduleToStartTimeout = 5 * time.Minute
+
+// The options below fit each kind of activity. Failures that retrying cannot
+// fix are returned by the activities as non-retryable errors, so the retry
+// policies only bound how long transient failures are retried.
+
+// storageOptions are for reading and writing artifacts, which only fail when
+// the storage is briefly unreachable.
+func storageOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: settings.ActivityTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    10,
+		},
+	})
+}
+
+// lookupOptions are for listing playlists and channels, resolving sources and
+// searching, which call yt-dlp, feeds and search APIs.
+func lookupOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: settings.ActivityTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    2 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    time.Minute,
+			MaximumAttempts:    5,
+		},
+	})
+}
+
+// downloadOptions are for RetrieveAudio, whose retries resume the download.
+func downloadOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: settings.DownloadTimeout,
+		HeartbeatTimeout:    heartbeatTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    10 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    5 * time.Minute,
+			MaximumAttempts:    activity.DownloadAttempts,
+		},
+	})
+}
+
+// cleanupOptions are for RemoveExpiredArtifacts, which may walk the whole
+// storage. It heartbeats as it deletes and its retries continue where the
+// previous attempt stopped.
+func cleanupOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Hour,
+		HeartbeatTimeout:    heartbeatTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    30 * time.Second,
+			MaximumAttempts:    10,
+		},
+	})
+}
+
+// indexOptions are for embedding transcripts and summaries into the semantic
+// index, on the task queue of the worker that keeps it. Indexing the whole
+// library may take long, so it heartbeats between embedding requests, and
+// retries skip what an earlier attempt indexed.
+func indexOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		TaskQueue:              settings.IndexTaskQueue,
+		ScheduleToStartTimeout: indexScheduleToStartTimeout,
+		StartToCloseTimeout:    time.Hour,
+		HeartbeatTimeout:       heartbeatTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    5 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    2 * time.Minute,
+			MaximumAttempts:    6,
+		},
+	})
+}
+
+// processingOptions are for local ffmpeg work, which rarely succeeds on a
+// retry after failing once.
+func processingOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: settings.ActivityTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    5 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumAttempts:    3,
+		},
+	})
+}
+
+// transcriptionOptions are for transcribing one audio chunk.
+func transcriptionOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: settings.TranscriptionTimeout,
+		HeartbeatTimeout:    heartbeatTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    5 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    2 * time.Minute,
+			MaximumAttempts:    5,
+		},
+	})
+}
+
+// modelOptions are for chat model calls, which are often rate limited.
+// Rate limit errors carry the delay the API asked for.
+func modelOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: settings.ActivityTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    5 * time.Second,
+			BackoffCoefficient: 2,
+			MaximumInterval:    2 * time.Minute,
+			MaximumAttempts:    6,
+		},
+	})
+}
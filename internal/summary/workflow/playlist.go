@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/cache"
+	"api/internal/summary/render"
+	"api/internal/summary/source"
+	"context"
+	"fmt"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	defaultPlaylistConcurrency = 3
+	// maxPlaylistVideos is the most videos one playlist run summarizes. Every
+	// child adds events to the history of the run, so channels with thousands
+	// of uploads would otherwise exceed its size limit.
+	maxPlaylistVideos = 500
+)
+
+type PlaylistSummarizeWorkflowParams struct {
+	// URL of a playlist or channel.
+	URL    string
+	Format render.Format
+	Force  bool
+	// Concurrency is how many videos are summarized at once, 3 by default.
+	Concurrency int
+	// MaxVideos limits how many videos are summarized, from the start of the
+	// list. 0 and larger values mean maxPlaylistVideos.
+	MaxVideos int
+	// Languages are passed on to every SummarizeWorkflow.
+	Languages []string
+	// Style is passed on to every SummarizeWorkflow.
+	Style string
+}
+
+// PlaylistSummarizeWorkflow summarizes every video of a playlist or channel
+// as SummarizeWorkflow children and returns the storage key of an index
+// document linking all summaries. Videos that fail are listed in the index
+// with their error instead of failing the whole playlist.
+func PlaylistSummarizeWorkflow(ctx workflow.Context, params PlaylistSummarizeWorkflowParams) (indexKey string, err error) {
+	ctx = storageOptions(ctx)
+
+	maxVideos := params.MaxVideos
+
+	if maxVideos <= 0 || maxVideos > maxPlaylistVideos {
+		maxVideos = maxPlaylistVideos
+	}
+
+	var playlist activity.PlaylistInfo
+
+	err = workflow.ExecuteActivity(lookupOptions(ctx), activity.ListPlaylistVideos, params.URL, maxVideos).Get(ctx, &playlist)
+
+	if err != nil {
+		return "", err
+	}
+
+	concurrency := params.Concurrency
+
+	if concurrency <= 0 {
+		concurrency = defaultPlaylistConcurrency
+	}
+
+	parentID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	sem := workflow.NewSemaphore(ctx, int64(concurrency))
+	wg := workflow.NewWaitGroup(ctx)
+	entries := make([]activity.PlaylistIndexEntry, len(playlist.Videos))
+
+	for i, video := range playlist.Videos {
+		entries[i] = activity.PlaylistIndexEntry{
+			Title: video.Title,
+			URL:   video.URL,
+		}
+
+		wg.Add(1)
+
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			defer wg.Done()
+
+			if err := sem.Acquire(ctx, 1); err != nil {
+				entries[i].Error = FailureReason(err)
+				return
+			}
+
+			defer sem.Release(1)
+
+			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+				WorkflowID: fmt.Sprintf("%s-%d-%s", parentID, i, cache.VideoID(video.URL)),
+			})
+
+			err := workflow.ExecuteChildWorkflow(
+				childCtx,
+				SummarizeWorkflow,
+				SummarizeWorkflowParams{
+					Source:    source.FromURL(video.URL),
+					Title:     video.Title,
+					Format:    params.Format,
+					Force:     params.Force,
+					Languages: params.Languages,
+					Style:     params.Style,
+				},
+			).Get(ctx, &entries[i].SummaryKey)
+
+			if err != nil {
+				entries[i].Error = FailureReason(err)
+			}
+		})
+	}
+
+	wg.Wait(ctx)
+
+	title := playlist.Title
+
+	if title == "" {
+		title = params.URL
+	}
+
+	err = workflow.ExecuteActivity(
+		ctx,
+		(*activity.ArtifactActivities).WritePlaylistIndex,
+		fmt.Sprintf("index-%s", cache.VideoID(params.URL)),
+		activity.PlaylistIndex{
+			Title:   title,
+			URL:     params.URL,
+			Entries: entries,
+		},
+		params.Format,
+	).Get(ctx, &indexKey)
+
+	return indexKey, err
+}
+
+func StartPlaylistSummarizeWorkflow(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	params PlaylistSummarizeWorkflowParams,
+) (client.WorkflowRun, error) {
+	return temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        workflowID,
+			TaskQueue: settings.TaskQueue,
+		},
+		PlaylistSummarizeWorkflow,
+		params,
+	)
+}
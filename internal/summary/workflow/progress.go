@@ -0,0 +1,223 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+const QueryProgress = "progress"
+
+type Stage string
+
+const (
+	StageResolving    Stage = "resolving"
+	StageDownloading  Stage = "downloading"
+	StageTranscribing Stage = "transcribing"
+	StageSummarizing  Stage = "summarizing"
+	StageTranslating  Stage = "translating"
+	StageWriting      Stage = "writing"
+	StageCompleted    Stage = "completed"
+)
+
+// Stages are the stages of SummarizeWorkflow in the order they run. Runs
+// that were not asked for other languages skip StageTranslating.
+var Stages = []Stage{StageDownloading, StageTranscribing, StageSummarizing, StageTranslating, StageWriting}
+
+type StageProgress struct {
+	Stage Stage
+	// Done and Total count the steps of the stage, such as transcribed audio
+	// chunks, when it has more than one.
+	Done    int
+	Total   int
+	Percent float64
+	// Detail describes what the stage is doing right now.
+	Detail string
+	// Cached is set when the stage was skipped because its output was cached.
+	Cached     bool
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Elapsed returns how long the stage ran, or has been running at now.
+func (s StageProgress) Elapsed(now time.Time) time.Duration {
+	switch {
+	case s.StartedAt.IsZero():
+		return 0
+	case !s.FinishedAt.IsZero():
+		return s.FinishedAt.Sub(s.StartedAt)
+	default:
+		return now.Sub(s.StartedAt)
+	}
+}
+
+// Progress is the result of the QueryProgress query of SummarizeWorkflow.
+type Progress struct {
+	Stage     Stage
+	Title     string
+	StartedAt time.Time
+	Stages    []StageProgress
+}
+
+// Elapsed returns how long the workflow has been running at now.
+func (p Progress) Elapsed(now time.Time) time.Duration {
+	if p.StartedAt.IsZero() {
+		return 0
+	}
+
+	return now.Sub(p.StartedAt)
+}
+
+func (p *Progress) stage(stage Stage) *StageProgress {
+	for i := range p.Stages {
+		if p.Stages[i].Stage == stage {
+			return &p.Stages[i]
+		}
+	}
+
+	return &StageProgress{Stage: stage}
+}
+
+// progressTracker keeps the Progress of a SummarizeWorkflow run up to date.
+type progressTracker struct {
+	progress Progress
+}
+
+// newProgressTracker tracks the given stages, a subset of Stages. Updates
+// to other stages are ignored.
+func newProgressTracker(ctx workflow.Context, stages []Stage) (*progressTracker, error) {
+	t := &progressTracker{
+		progress: Progress{
+			Stage:     StageResolving,
+			StartedAt: workflow.GetInfo(ctx).WorkflowStartTime,
+			Stages:    make([]StageProgress, len(stages)),
+		},
+	}
+
+	for i, stage := range stages {
+		t.progress.Stages[i].Stage = stage
+	}
+
+	err := workflow.SetQueryHandler(ctx, QueryProgress, func() (Progress, error) {
+		return t.progress, nil
+	})
+
+	return t, err
+}
+
+func (t *progressTracker) stage(stage Stage) *StageProgress {
+	return t.progress.stage(stage)
+}
+
+// start marks stage as running with total steps, 0 when it has no steps.
+func (t *progressTracker) start(ctx workflow.Context, stage Stage, total int) {
+	s := t.stage(stage)
+	s.Total = total
+	s.StartedAt = workflow.Now(ctx)
+	t.progress.Stage = stage
+}
+
+// addSteps grows the number of steps of a running stage.
+func (t *progressTracker) addSteps(stage Stage, n int) {
+	s := t.stage(stage)
+	s.Total += n
+	s.Percent = percent(s.Done, s.Total)
+}
+
+func (t *progressTracker) step(stage Stage) {
+	s := t.stage(stage)
+	s.Done++
+	s.Percent = percent(s.Done, s.Total)
+}
+
+func (t *progressTracker) finish(ctx workflow.Context, stage Stage) {
+	s := t.stage(stage)
+	s.Percent = 100
+	s.Detail = ""
+	s.FinishedAt = workflow.Now(ctx)
+}
+
+// cached marks stages as skipped because their output was cached.
+func (t *progressTracker) cached(stages ...Stage) {
+	for _, stage := range stages {
+		s := t.stage(stage)
+		s.Cached = true
+		s.Percent = 100
+	}
+}
+
+func (t *progressTracker) complete() {
+	t.progress.Stage = StageCompleted
+}
+
+func percent(done, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+
+	return float64(done) / float64(total) * 100
+}
+
+// GetProgress returns the progress of a SummarizeWorkflow run. The percentage
+// of a running download and of the chunks being transcribed comes from the
+// heartbeats of their activities, the other stages are counted by the
+// workflow.
+func GetProgress(ctx context.Context, temporalClient client.Client, workflowID string, runID string) (Progress, error) {
+	var progress Progress
+
+	result, err := temporalClient.QueryWorkflow(ctx, workflowID, runID, QueryProgress)
+
+	if err != nil {
+		return progress, err
+	}
+
+	if err := result.Get(&progress); err != nil {
+		return progress, err
+	}
+
+	if progress.Stage != StageDownloading && progress.Stage != StageTranscribing {
+		return progress, nil
+	}
+
+	resp, err := temporalClient.DescribeWorkflowExecution(ctx, workflowID, runID)
+
+	if err != nil {
+		return progress, err
+	}
+
+	s := progress.stage(progress.Stage)
+	// transcribing sums the fractions of the chunks being transcribed.
+	transcribing := 0.0
+
+	for _, pending := range resp.GetPendingActivities() {
+		if pending.GetHeartbeatDetails() == nil {
+			continue
+		}
+
+		var heartbeat activity.Progress
+
+		err := converter.GetDefaultDataConverter().FromPayloads(pending.GetHeartbeatDetails(), &heartbeat)
+
+		if err != nil {
+			continue
+		}
+
+		switch name := pending.GetActivityType().GetName(); {
+		case progress.Stage == StageDownloading && name == "RetrieveAudio":
+			s.Percent = heartbeat.Percent
+			s.Detail = heartbeat.Detail
+		case progress.Stage == StageTranscribing && name == "TranscribeAudio":
+			transcribing += heartbeat.Percent / 100
+		}
+	}
+
+	if progress.Stage == StageTranscribing && s.Total > 0 {
+		s.Percent = (float64(s.Done) + transcribing) / float64(s.Total) * 100
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,41 @@
+package workflow
+
+import (
+	"go.temporal.io/sdk/workflow"
+)
+
+// saga collects the compensations of the steps of a workflow run that leave
+// artifacts behind, to undo them when the run fails or is canceled.
+type saga struct {
+	compensations []compensation
+}
+
+type compensation struct {
+	activity any
+	args     []any
+}
+
+// add registers an activity that undoes a step. Register it before the step
+// runs, as a step that fails or is canceled halfway can leave artifacts too.
+func (s *saga) add(activity any, args ...any) {
+	s.compensations = append(s.compensations, compensation{activity, args})
+}
+
+// compensate runs the compensations in reverse order. It uses a disconnected
+// context so it also runs when the workflow was canceled. Failed
+// compensations are logged and skipped, they must not hide the original
+// error.
+func (s *saga) compensate(ctx workflow.Context) {
+	ctx, cancel := workflow.NewDisconnectedContext(ctx)
+	defer cancel()
+
+	ctx = storageOptions(ctx)
+
+	for i := len(s.compensations) - 1; i >= 0; i-- {
+		c := s.compensations[i]
+
+		if err := workflow.ExecuteActivity(ctx, c.activity, c.args...).Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Warn("Compensation failed", "Error", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/shared"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+// SearchWorkflow runs one search against the configured search providers.
+// Unlike InteractiveWorkflow it asks no questions, so it suits scripts.
+func SearchWorkflow(ctx workflow.Context, params shared.SearchParams) (results []shared.SearchResult, err error) {
+	ctx = lookupOptions(ctx)
+
+	err = workflow.ExecuteActivity(ctx, (*activity.SearchActivities).SearchProviders, params).Get(ctx, &results)
+
+	return results, err
+}
+
+func ExecuteSearchWorkflow(
+	ctx context.Context,
+	temporalClient client.Client,
+	params shared.SearchParams,
+) ([]shared.SearchResult, error) {
+	run, err := temporalClient.ExecuteWorkflow(
+		ctx,
+		client.StartWorkflowOptions{
+			ID:        fmt.Sprintf("search-workflow-%s", uuid.New().String()),
+			TaskQueue: settings.TaskQueue,
+		},
+		SearchWorkflow,
+		params,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []shared.SearchResult
+
+	err = run.Get(ctx, &results)
+
+	return results, err
+}
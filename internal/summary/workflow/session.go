@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// sessionQueryConcurrency is how many sessions ListOpenSessions queries at
+// once.
+const sessionQueryConcurrency = 10
+
+// Session is an InteractiveWorkflow that is still waiting on its user.
+type Session struct {
+	ID        string
+	RunID     string
+	StartTime time.Time
+	State     InteractiveWorkflowState
+	// QueryError says why State could not be queried, such as a worker that
+	// is down. State is empty then.
+	QueryError string
+}
+
+// NewSessionID returns the workflow ID of a new InteractiveWorkflow session.
+func NewSessionID() string {
+	return fmt.Sprintf("interaction-workflow-%s", uuid.New().String())
+}
+
+// SessionSummaryWorkflowID is the ID of the SummarizeWorkflow started for the
+// search result selected in a session, so clients can find it again after
+// the session has completed.
+func SessionSummaryWorkflowID(sessionID string) string {
+	return fmt.Sprintf("summarize-workflow-%s", sessionID)
+}
+
+// QuerySessionState returns the current state of a session. It also works
+// for completed sessions, as long as their history is retained.
+func QuerySessionState(
+	ctx context.Context,
+	temporalClient client.Client,
+	workflowID string,
+	runID string,
+) (InteractiveWorkflowState, error) {
+	var state InteractiveWorkflowState
+
+	result, err := temporalClient.QueryWorkflow(ctx, workflowID, runID, QueryCheckState)
+
+	if err != nil {
+		return state, err
+	}
+
+	err = result.Get(&state)
+
+	return state, err
+}
+
+// ListOpenSessions returns the running sessions, newest first. Sessions are
+// queried concurrently, and those whose query fails are listed with a
+// QueryError.
+func ListOpenSessions(ctx context.Context, temporalClient client.Client) ([]Session, error) {
+	sessions := make([]Session, 0)
+	req := &workflowservice.ListWorkflowExecutionsRequest{
+		Query: "WorkflowType = 'InteractiveWorkflow' AND ExecutionStatus = 'Running'",
+	}
+
+	for {
+		resp, err := temporalClient.ListWorkflow(ctx, req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		page := make([]Session, len(resp.GetExecutions()))
+		limit := make(chan struct{}, sessionQueryConcurrency)
+
+		var wg sync.WaitGroup
+
+		for i, info := range resp.GetExecutions() {
+			page[i] = Session{
+				ID:        info.GetExecution().GetWorkflowId(),
+				RunID:     info.GetExecution().GetRunId(),
+				StartTime: info.GetStartTime().AsTime(),
+			}
+
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				limit <- struct{}{}
+				defer func() { <-limit }()
+
+				s := &page[i]
+				state, err := QuerySessionState(ctx, temporalClient, s.ID, s.RunID)
+
+				if err != nil {
+					s.QueryError = err.Error()
+					return
+				}
+
+				s.State = state
+			}()
+		}
+
+		wg.Wait()
+
+		sessions = append(sessions, page...)
+
+		if len(resp.GetNextPageToken()) == 0 {
+			return sessions, nil
+		}
+
+		req.NextPageToken = resp.GetNextPageToken()
+	}
+}
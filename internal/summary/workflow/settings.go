@@ -0,0 +1,35 @@
+package workflow
+
+import "time"
+
+// Settings are the deployment specific values used by the workflows and by
+// the helpers that start them.
+type Settings struct {
+	TaskQueue string
+	// IndexTaskQueue is served by the one worker that keeps the semantic
+	// index, which the index activities run on.
+	IndexTaskQueue string
+	// ActivityTimeout is the StartToClose timeout of most activities.
+	ActivityTimeout time.Duration
+	// TranscriptionTimeout is the StartToClose timeout of each audio chunk
+	// transcription.
+	TranscriptionTimeout time.Duration
+	// DownloadTimeout is the StartToClose timeout of each audio download
+	// attempt.
+	DownloadTimeout time.Duration
+}
+
+var settings = Settings{
+	TaskQueue:            "summarize",
+	IndexTaskQueue:       "summarize-index",
+	ActivityTimeout:      time.Minute * 5,
+	TranscriptionTimeout: time.Minute * 15,
+	DownloadTimeout:      time.Hour,
+}
+
+// Configure replaces the default settings. Call it once at startup, before
+// any workflow runs or is started. Timeouts only apply to activities
+// scheduled afterwards, so changing them never breaks workflow replay.
+func Configure(s Settings) {
+	settings = s
+}
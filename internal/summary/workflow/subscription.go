@@ -0,0 +1,237 @@
+package workflow
+
+import (
+	"api/internal/summary/activity"
+	"api/internal/summary/cache"
+	"api/internal/summary/render"
+	"api/internal/summary/source"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	subscriptionSchedulePrefix = "subscription-"
+	// defaultSubscriptionMaxVideos is how many of the latest uploads are
+	// checked on every poll.
+	defaultSubscriptionMaxVideos = 10
+)
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+type SubscriptionParams struct {
+	// Channel is a channel URL, handle or channel ID.
+	Channel string
+	Format  render.Format
+	// MaxVideos is how many of the latest uploads are checked on every poll,
+	// 10 by default.
+	MaxVideos int
+	// Languages are passed on to every SummarizeWorkflow.
+	Languages []string
+	// Style is passed on to every SummarizeWorkflow.
+	Style string
+}
+
+type Subscription struct {
+	ID       string
+	Channel  string
+	Interval time.Duration
+	LastPoll time.Time
+	NextPoll time.Time
+}
+
+// SubscriptionPollWorkflow is started by the subscription's schedule. It
+// starts a SummarizeWorkflow for every upload of the channel that was not
+// processed before and returns how many were started. The first poll only
+// records the existing uploads, so subscribing does not summarize the
+// channel's back catalogue.
+func SubscriptionPollWorkflow(ctx workflow.Context, params SubscriptionParams) (started int, err error) {
+	ctx = storageOptions(ctx)
+
+	subscriptionID := SubscriptionID(params.Channel)
+	maxVideos := params.MaxVideos
+
+	if maxVideos <= 0 {
+		maxVideos = defaultSubscriptionMaxVideos
+	}
+
+	var uploads activity.ChannelUploads
+
+	err = workflow.ExecuteActivity(lookupOptions(ctx), activity.ListChannelUploads, params.Channel, maxVideos).Get(ctx, &uploads)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var newVideos activity.NewVideos
+
+	err = workflow.ExecuteActivity(
+		ctx,
+		(*activity.ArtifactActivities).FilterProcessedVideos,
+		subscriptionID,
+		uploads.Videos,
+	).Get(ctx, &newVideos)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !newVideos.FirstPoll {
+		for _, video := range newVideos.Videos {
+			// The child outlives the poll and its ID is derived from the video,
+			// so a retried poll can never summarize a video twice.
+			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+				WorkflowID:            fmt.Sprintf("%s%s-%s", subscriptionSchedulePrefix, subscriptionID, cache.VideoID(video.URL)),
+				ParentClosePolicy:     enums.PARENT_CLOSE_POLICY_ABANDON,
+				WorkflowIDReusePolicy: enums.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+			})
+
+			err = workflow.ExecuteChildWorkflow(
+				childCtx,
+				SummarizeWorkflow,
+				SummarizeWorkflowParams{
+					Source:    source.FromURL(video.URL),
+					Title:     video.Title,
+					Format:    params.Format,
+					Languages: params.Languages,
+					Style:     params.Style,
+				},
+			).GetChildWorkflowExecution().Get(ctx, nil)
+
+			if err != nil && !temporal.IsWorkflowExecutionAlreadyStartedError(err) {
+				return started, err
+			}
+
+			started++
+		}
+	}
+
+	err = workflow.ExecuteActivity(
+		ctx,
+		(*activity.ArtifactActivities).MarkVideosProcessed,
+		subscriptionID,
+		newVideos.Videos,
+	).Get(ctx, nil)
+
+	return started, err
+}
+
+// SubscriptionID derives a stable identifier from a channel URL, handle or
+// channel ID, so the same channel always maps to the same schedule.
+func SubscriptionID(channel string) string {
+	channel = strings.TrimSpace(channel)
+
+	if u, err := url.Parse(channel); err == nil && u.Host != "" {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		channel = parts[0]
+
+		if len(parts) >= 2 && (parts[0] == "channel" || parts[0] == "user" || parts[0] == "c") {
+			channel = parts[1]
+		}
+	}
+
+	// Handles are case-insensitive, channel IDs are not.
+	if !strings.HasPrefix(channel, "UC") || len(channel) != 24 {
+		channel = strings.ToLower(channel)
+	}
+
+	return unsafeIDChars.ReplaceAllString(strings.TrimPrefix(channel, "@"), "-")
+}
+
+// CreateSubscription schedules SubscriptionPollWorkflow to poll the channel
+// every interval, starting right away.
+func CreateSubscription(
+	ctx context.Context,
+	temporalClient client.Client,
+	params SubscriptionParams,
+	interval time.Duration,
+) (string, error) {
+	subscriptionID := SubscriptionID(params.Channel)
+
+	if subscriptionID == "" {
+		return "", fmt.Errorf("invalid channel %q", params.Channel)
+	}
+
+	_, err := temporalClient.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID: subscriptionSchedulePrefix + subscriptionID,
+		Spec: client.ScheduleSpec{
+			Intervals: []client.ScheduleIntervalSpec{{Every: interval}},
+		},
+		Action: &client.ScheduleWorkflowAction{
+			ID:        fmt.Sprintf("subscription-poll-%s", subscriptionID),
+			Workflow:  SubscriptionPollWorkflow,
+			Args:      []interface{}{params},
+			TaskQueue: settings.TaskQueue,
+		},
+		Overlap:            enums.SCHEDULE_OVERLAP_POLICY_SKIP,
+		TriggerImmediately: true,
+		Memo: map[string]interface{}{
+			"channel": params.Channel,
+		},
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return subscriptionID, nil
+}
+
+// DeleteSubscription stops polling the channel. Summaries that were already
+// started keep running.
+func DeleteSubscription(ctx context.Context, temporalClient client.Client, subscriptionID string) error {
+	return temporalClient.ScheduleClient().GetHandle(ctx, subscriptionSchedulePrefix+subscriptionID).Delete(ctx)
+}
+
+func ListSubscriptions(ctx context.Context, temporalClient client.Client) ([]Subscription, error) {
+	iter, err := temporalClient.ScheduleClient().List(ctx, client.ScheduleListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]Subscription, 0)
+
+	for iter.HasNext() {
+		entry, err := iter.Next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(entry.ID, subscriptionSchedulePrefix) {
+			continue
+		}
+
+		s := Subscription{ID: strings.TrimPrefix(entry.ID, subscriptionSchedulePrefix)}
+
+		if payload, ok := entry.Memo.GetFields()["channel"]; ok {
+			converter.GetDefaultDataConverter().FromPayload(payload, &s.Channel)
+		}
+
+		if entry.Spec != nil && len(entry.Spec.Intervals) > 0 {
+			s.Interval = entry.Spec.Intervals[0].Every
+		}
+
+		if n := len(entry.RecentActions); n > 0 {
+			s.LastPoll = entry.RecentActions[n-1].ActualTime
+		}
+
+		if len(entry.NextActionTimes) > 0 {
+			s.NextPoll = entry.NextActionTimes[0]
+		}
+
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, nil
+}
@@ -2,61 +2,144 @@ package workflow
 
 import (
 	"api/internal/summary/activity"
+	"api/internal/summary/language"
+	"api/internal/summary/render"
+	"api/internal/summary/source"
+	"api/internal/summary/style"
+	"api/internal/summary/transcriber"
 	"context"
 	"fmt"
-	"time"
+	"slices"
+	"strings"
 
+	"go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
 type SummarizeWorkflowParams struct {
-	URL string
+	Source source.Ref
+	// Title defaults to the title of the source, if it has one.
+	Title  string
+	Format render.Format
+	// Force re-runs every step even when its output is already cached.
+	Force bool
+	// Languages are the ISO 639-1 codes or English names of the languages
+	// to translate the summary into, each one written to LanguageOutputKey.
+	// The returned key holds the summary in the spoken language.
+	Languages []string
+	// Style is the summary style, "name" or "name@version", the latest
+	// version of the default style when empty.
+	Style string
 }
 
-func SummarizeWorkflow(ctx workflow.Context, params SummarizeWorkflowParams) (outputPath string, err error) {
-	ao := workflow.ActivityOptions{
-		StartToCloseTimeout: time.Minute * 5,
+// SummarizeWorkflow summarizes a single source and returns the storage key of
+// the rendered summary. When it fails or is canceled, the artifacts it left
+// behind (audio chunks, output files that did not exist before) are removed.
+func SummarizeWorkflow(ctx workflow.Context, params SummarizeWorkflowParams) (outputKey string, err error) {
+	ctx = storageOptions(ctx)
+
+	var cleanup saga
+
+	defer func() {
+		if err != nil {
+			cleanup.compensate(ctx)
+		}
+	}()
+
+	languages, err := language.Parse(params.Languages)
+
+	if err != nil {
+		return "", temporal.NewNonRetryableApplicationError(err.Error(), activity.ErrTypeInvalidRequest, err)
 	}
 
-	ctx = workflow.WithActivityOptions(ctx, ao)
+	stages := Stages
 
-	var retrieveAudioResult activity.RetrieveAudioResult
+	if len(languages) == 0 {
+		stages = slices.DeleteFunc(slices.Clone(Stages), func(s Stage) bool { return s == StageTranslating })
+	}
 
-	err = workflow.ExecuteActivity(ctx, activity.RetrieveAudio, params.URL).Get(ctx, &retrieveAudioResult)
+	tracker, err := newProgressTracker(ctx, stages)
 
 	if err != nil {
 		return "", err
 	}
 
-	var transcriptionText string
+	var resolved source.Item
 
-	err = workflow.ExecuteActivity(
-		ctx,
-		(*activity.AudioProcessActivities).TranscribeAudio,
-		retrieveAudioResult.OutputPath,
-	).Get(ctx, &transcriptionText)
+	err = workflow.ExecuteActivity(lookupOptions(ctx), activity.ResolveSource, params.Source).Get(ctx, &resolved)
+
+	if err != nil {
+		return "", err
+	}
+
+	title := params.Title
+
+	if title == "" {
+		title = resolved.Title
+	}
+
+	tracker.progress.Title = title
+
+	videoID := resolved.Ref.ID()
+
+	var styleRef style.Ref
+
+	err = workflow.ExecuteActivity(ctx, (*activity.AudioProcessActivities).ResolveStyle, params.Style).Get(ctx, &styleRef)
 
 	if err != nil {
 		return "", err
 	}
 
+	// Summaries in other styles are cached and written next to the default
+	// one rather than over it.
+	styleName := styleRef.CacheName()
+	fileName := videoID
+
+	if styleName != "" {
+		fileName += "." + styleName
+	}
+
+	var cached activity.CachedArtifacts
+
+	if !params.Force {
+		err = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).LookupCache, videoID, styleName).Get(ctx, &cached)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	summaryOutputKey, err := activity.SummaryOutputKey(fileName, params.Format)
+
+	if err != nil {
+		return "", temporal.NewNonRetryableApplicationError(err.Error(), activity.ErrTypeInvalidRequest, err)
+	}
+
 	var futures struct {
-		summarizeActivity               workflow.Future
-		createSummaryOutputFileActivity workflow.Future
+		summarizeActivity workflow.Future
 	}
 
-	futures.summarizeActivity = workflow.ExecuteActivity(
-		ctx,
-		(*activity.AudioProcessActivities).SummarizeTranscription,
-		transcriptionText,
-	)
+	spoken := cached.Language
 
-	futures.createSummaryOutputFileActivity = workflow.ExecuteActivity(
-		ctx,
-		activity.CreateSummaryOutputFile,
-		retrieveAudioResult.FileName,
-	)
+	if cached.SummaryKey != "" {
+		tracker.cached(StageDownloading, StageTranscribing, StageSummarizing)
+		futures.summarizeActivity = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).LoadCachedSummary, videoID, styleName)
+	} else {
+		transcript, err := transcribe(ctx, tracker, &cleanup, resolved.Ref, videoID, cached)
+
+		if err != nil {
+			return "", err
+		}
+
+		if transcript.Language != "" {
+			spoken = transcript.Language
+		}
+
+		tracker.start(ctx, StageSummarizing, 0)
+		futures.summarizeActivity = summarizeTranscript(ctx, tracker, transcript, styleRef)
+	}
 
 	var summary string
 
@@ -66,52 +149,476 @@ func SummarizeWorkflow(ctx workflow.Context, params SummarizeWorkflowParams) (ou
 		return "", err
 	}
 
-	var summaryOutputPath string
+	if cached.SummaryKey == "" {
+		tracker.finish(ctx, StageSummarizing)
+	}
 
-	err = futures.createSummaryOutputFileActivity.Get(ctx, &summaryOutputPath)
+	// Cached translations are only reused together with the summary they
+	// were translated from.
+	translations, err := translate(ctx, tracker, videoID, styleName, summary, spoken, languages, cached.SummaryKey != "")
 
 	if err != nil {
 		return "", err
 	}
 
+	tracker.start(ctx, StageWriting, 0)
+
+	if cached.SummaryKey == "" {
+		err = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).CacheSummary, videoID, styleName, summary).Get(ctx, nil)
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = workflow.ExecuteActivity(
+		ctx,
+		(*activity.ArtifactActivities).CacheMetadata,
+		videoID,
+		source.Item{Ref: resolved.Ref, Title: title, Language: spoken},
+	).Get(ctx, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	document := func(markdown string, lang string) render.Document {
+		return render.Document{
+			Title:    title,
+			URL:      resolved.Ref.Link(),
+			Markdown: activity.LinkTimestamps(markdown, resolved.Ref.Link()),
+			Language: lang,
+		}
+	}
+
+	outputKeys := []string{summaryOutputKey}
+
+	for _, lang := range languages {
+		outputKeys = append(outputKeys, activity.LanguageOutputKey(summaryOutputKey, lang))
+	}
+
+	// Output keys are shared by every run of the video, so only those this
+	// run creates are removed when it fails.
+	var created []string
+
+	err = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).MissingOutputFiles, outputKeys).Get(ctx, &created)
+
+	if err != nil {
+		return "", err
+	}
+
+	cleanup.add((*activity.ArtifactActivities).RemoveOutputFiles, created)
+
 	var isSummarySuccess bool
 
 	err = workflow.ExecuteActivity(
 		ctx,
-		activity.OutputSummaryToFile,
-		summary,
-		summaryOutputPath,
+		(*activity.ArtifactActivities).OutputSummaryToFile,
+		document(summary, spoken),
+		params.Format,
+		summaryOutputKey,
 	).Get(ctx, &isSummarySuccess)
 
 	if err != nil {
 		return "", err
 	}
 
-	outputPath = summaryOutputPath
-	return outputPath, nil
+	outputFutures := make([]workflow.Future, len(languages))
+
+	for i, lang := range languages {
+		outputFutures[i] = workflow.ExecuteActivity(
+			ctx,
+			(*activity.ArtifactActivities).OutputSummaryToFile,
+			document(translations[lang], lang),
+			params.Format,
+			activity.LanguageOutputKey(summaryOutputKey, lang),
+		)
+	}
+
+	if err := awaitAll(ctx, outputFutures, make([]bool, len(languages)), func() {}); err != nil {
+		return "", err
+	}
+
+	tracker.finish(ctx, StageWriting)
+
+	// The summary is written, so it does not wait for the index, and failing
+	// to index it only leaves it out of find results until the library is
+	// indexed again.
+	indexCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        fmt.Sprintf("index-video-%s-%s", videoID, workflow.GetInfo(ctx).WorkflowExecution.RunID),
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+
+	err = workflow.ExecuteChildWorkflow(indexCtx, IndexVideoWorkflow, videoID, styleName).GetChildWorkflowExecution().Get(ctx, nil)
+
+	if temporal.IsCanceledError(err) {
+		return "", err
+	}
+
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to start indexing the summary", "Error", err)
+	}
+
+	tracker.complete()
+
+	outputKey = summaryOutputKey
+	return outputKey, nil
+}
+
+// transcribe returns the cached transcript of the video when there is one.
+// Otherwise it transcribes the cached audio, downloading it first if needed,
+// and caches the result. The chunks split by this run are registered with
+// cleanup. The audio stays cached for the next run whether this one succeeds
+// or not, shared with concurrent runs of the video, and is removed by the
+// retention policy.
+func transcribe(
+	ctx workflow.Context,
+	tracker *progressTracker,
+	cleanup *saga,
+	ref source.Ref,
+	videoID string,
+	cached activity.CachedArtifacts,
+) (transcript transcriber.Transcript, err error) {
+	if cached.TranscriptKey != "" {
+		tracker.cached(StageDownloading, StageTranscribing)
+		err = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).LoadCachedTranscript, videoID).Get(ctx, &transcript)
+		return transcript, err
+	}
+
+	audioKey := cached.AudioKey
+
+	if audioKey != "" {
+		tracker.cached(StageDownloading)
+	} else {
+		tracker.start(ctx, StageDownloading, 0)
+
+		var retrieveAudioResult activity.RetrieveAudioResult
+
+		err = workflow.ExecuteActivity(
+			downloadOptions(ctx),
+			(*activity.ArtifactActivities).RetrieveAudio,
+			ref,
+			videoID,
+		).Get(ctx, &retrieveAudioResult)
+
+		if err != nil {
+			return transcript, err
+		}
+
+		audioKey = retrieveAudioResult.OutputKey
+		tracker.finish(ctx, StageDownloading)
+	}
+
+	tracker.start(ctx, StageTranscribing, 0)
+
+	// Chunks are stored under the run ID, so a concurrent run of the same
+	// video never removes the chunks this one transcribes.
+	runID := workflow.GetInfo(ctx).WorkflowExecution.RunID
+	cleanup.add((*activity.ArtifactActivities).RemoveAllAudioChunks, audioKey, runID)
+
+	var chunks []activity.AudioChunk
+
+	err = workflow.ExecuteActivity(
+		processingOptions(ctx),
+		(*activity.ArtifactActivities).SplitAudio,
+		audioKey,
+		runID,
+	).Get(ctx, &chunks)
+
+	if err != nil {
+		return transcript, err
+	}
+
+	tracker.addSteps(StageTranscribing, len(chunks))
+
+	transcribeCtx := transcriptionOptions(ctx)
+	transcribeFutures := make([]workflow.Future, len(chunks))
+
+	for i, chunk := range chunks {
+		transcribeFutures[i] = workflow.ExecuteActivity(
+			transcribeCtx,
+			(*activity.AudioProcessActivities).TranscribeAudio,
+			chunk.Key,
+		)
+	}
+
+	transcripts := make([]transcriber.Transcript, len(chunks))
+
+	err = awaitAll(ctx, transcribeFutures, transcripts, func() {
+		tracker.step(StageTranscribing)
+	})
+
+	if err != nil {
+		return transcript, err
+	}
+
+	transcript = activity.StitchTranscripts(chunks, transcripts)
+
+	err = workflow.ExecuteActivity(
+		ctx,
+		(*activity.ArtifactActivities).RemoveAudioChunks,
+		audioKey,
+		chunks,
+	).Get(ctx, nil)
+
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to remove audio chunks", "Error", err)
+	}
+
+	err = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).CacheTranscript, videoID, transcript).Get(ctx, nil)
+
+	if err != nil {
+		return transcript, err
+	}
+
+	tracker.finish(ctx, StageTranscribing)
+
+	return transcript, nil
+}
+
+// translate returns the summary in each of languages, translating it in
+// parallel into the ones other than the spoken language. Translations are
+// cached with the summary's style, and cached ones are used when useCache is
+// set.
+func translate(
+	ctx workflow.Context,
+	tracker *progressTracker,
+	videoID string,
+	styleName string,
+	summary string,
+	spoken string,
+	languages []string,
+	useCache bool,
+) (map[string]string, error) {
+	translations := make(map[string]string, len(languages))
+
+	if len(languages) == 0 {
+		return translations, nil
+	}
+
+	tracker.start(ctx, StageTranslating, 0)
+
+	pending := make([]string, 0, len(languages))
+	futures := make([]workflow.Future, 0, len(languages))
+
+	for _, lang := range languages {
+		if lang == spoken {
+			translations[lang] = summary
+			continue
+		}
+
+		future, settable := workflow.NewFuture(ctx)
+
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			settable.Set(translateSummary(ctx, videoID, styleName, summary, lang, useCache))
+		})
+
+		pending = append(pending, lang)
+		futures = append(futures, future)
+	}
+
+	tracker.addSteps(StageTranslating, len(futures))
+	results := make([]string, len(futures))
+
+	err := awaitAll(ctx, futures, results, func() {
+		tracker.step(StageTranslating)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, lang := range pending {
+		translations[lang] = results[i]
+	}
+
+	tracker.finish(ctx, StageTranslating)
+
+	return translations, nil
+}
+
+func translateSummary(
+	ctx workflow.Context,
+	videoID string,
+	styleName string,
+	summary string,
+	lang string,
+	useCache bool,
+) (string, error) {
+	var translation string
+
+	if useCache {
+		err := workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).LoadCachedTranslation, videoID, styleName, lang).Get(ctx, &translation)
+
+		if err != nil || translation != "" {
+			return translation, err
+		}
+	}
+
+	err := workflow.ExecuteActivity(
+		modelOptions(ctx),
+		(*activity.AudioProcessActivities).TranslateSummary,
+		summary,
+		lang,
+	).Get(ctx, &translation)
+
+	if err != nil {
+		return "", err
+	}
+
+	err = workflow.ExecuteActivity(ctx, (*activity.ArtifactActivities).CacheTranslation, videoID, styleName, lang, translation).Get(ctx, nil)
+
+	return translation, err
+}
+
+// summarizeTranscript summarizes short transcripts in a single prompt. Longer
+// ones are split into sections that are summarized in parallel and then
+// merged, repeating the merge until the partial summaries fit one prompt.
+// Every prompt is rendered from the same version of the style.
+func summarizeTranscript(
+	ctx workflow.Context,
+	tracker *progressTracker,
+	transcript transcriber.Transcript,
+	styleRef style.Ref,
+) workflow.Future {
+	text := transcript.TimestampedText()
+
+	if transcriber.EstimateTokens(text) <= activity.SinglePassTokenLimit {
+		tracker.addSteps(StageSummarizing, 1)
+
+		return workflow.ExecuteActivity(
+			modelOptions(ctx),
+			(*activity.AudioProcessActivities).SummarizeTranscription,
+			text,
+			transcript.Language,
+			styleRef,
+		)
+	}
+
+	future, settable := workflow.NewFuture(ctx)
+
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		settable.Set(mapReduceSummary(ctx, tracker, transcript, styleRef))
+	})
+
+	return future
+}
+
+func mapReduceSummary(
+	ctx workflow.Context,
+	tracker *progressTracker,
+	transcript transcriber.Transcript,
+	styleRef style.Ref,
+) (string, error) {
+	sections := transcript.Sections(activity.SectionTokenLimit)
+	step := func() { tracker.step(StageSummarizing) }
+
+	// Every section, plus the final merge.
+	tracker.addSteps(StageSummarizing, len(sections)+1)
+	sectionFutures := make([]workflow.Future, len(sections))
+
+	for i, section := range sections {
+		sectionFutures[i] = workflow.ExecuteActivity(
+			modelOptions(ctx),
+			(*activity.AudioProcessActivities).SummarizeTranscriptSection,
+			section.TimestampedText(),
+			i+1,
+			len(sections),
+			transcript.Language,
+			styleRef,
+		)
+	}
+
+	summaries := make([]string, len(sections))
+
+	if err := awaitAll(ctx, sectionFutures, summaries, step); err != nil {
+		return "", err
+	}
+
+	for len(summaries) > 1 && transcriber.EstimateTokens(strings.Join(summaries, "\n")) > activity.SinglePassTokenLimit {
+		// Groups of at least two notes make every round of merging shorter.
+		groups := transcriber.GroupByTokens(summaries, activity.SinglePassTokenLimit, 2)
+		tracker.addSteps(StageSummarizing, len(groups))
+		mergeFutures := make([]workflow.Future, len(groups))
+
+		for i, group := range groups {
+			mergeFutures[i] = workflow.ExecuteActivity(
+				modelOptions(ctx),
+				(*activity.AudioProcessActivities).MergeSectionSummaries,
+				group,
+				transcript.Language,
+				styleRef,
+			)
+		}
+
+		summaries = make([]string, len(groups))
+
+		if err := awaitAll(ctx, mergeFutures, summaries, step); err != nil {
+			return "", err
+		}
+	}
+
+	var summary string
+
+	err := workflow.ExecuteActivity(
+		modelOptions(ctx),
+		(*activity.AudioProcessActivities).MergeSectionSummaries,
+		summaries,
+		transcript.Language,
+		styleRef,
+	).Get(ctx, &summary)
+
+	if err != nil {
+		return "", err
+	}
+
+	step()
+
+	return summary, nil
 }
 
-func ExecuteSummarizeWorkflow(
+// awaitAll gets the result of every future into results, calling onDone as
+// each one completes. It returns the first error without waiting for the
+// remaining futures.
+func awaitAll[T any](ctx workflow.Context, futures []workflow.Future, results []T, onDone func()) error {
+	var firstErr error
+
+	selector := workflow.NewSelector(ctx)
+
+	for i, future := range futures {
+		selector.AddFuture(future, func(f workflow.Future) {
+			if err := f.Get(ctx, &results[i]); err != nil && firstErr == nil {
+				firstErr = err
+			}
+
+			onDone()
+		})
+	}
+
+	for range futures {
+		selector.Select(ctx)
+
+		if firstErr != nil {
+			return firstErr
+		}
+	}
+
+	return nil
+}
+
+func StartSummarizeWorkflow(
 	ctx context.Context,
 	temporalClient client.Client,
+	workflowID string,
 	params SummarizeWorkflowParams,
-) (string, error) {
-	res, err := temporalClient.ExecuteWorkflow(
+) (client.WorkflowRun, error) {
+	return temporalClient.ExecuteWorkflow(
 		ctx,
 		client.StartWorkflowOptions{
-			ID:        fmt.Sprintf("summarize-workflow-%s", time.Now().Format("20060102150405")),
-			TaskQueue: "summarize",
+			ID:        workflowID,
+			TaskQueue: settings.TaskQueue,
 		},
 		SummarizeWorkflow,
-		SummarizeWorkflowParams{URL: params.URL},
+		params,
 	)
-
-	if err != nil {
-		return "", err
-	}
-
-	var outputPath string
-	res.Get(ctx, &outputPath)
-
-	return outputPath, nil
 }
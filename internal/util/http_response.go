@@ -15,3 +15,10 @@ func JSONError(w http.ResponseWriter, err ErrorParam, code int) {
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(err)
 }
+
+func JSONResponse(w http.ResponseWriter, body any, code int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}